@@ -0,0 +1,140 @@
+// Package blender finds Blender installs and their versions
+package blender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xackery/eqgzi-manager/release"
+)
+
+// versionPattern matches the first line of blender --version
+var versionPattern = regexp.MustCompile(`(?m)^Blender (\d+\.\d+(?:\.\d+)?)`)
+
+// Install is a Blender found on this machine
+type Install struct {
+	// Path is the Blender executable
+	Path string
+	// Version is reported by blender --version, empty if it failed
+	Version string
+	// Source is where the install was found, e.g. PATH, snap, flatpak or steam
+	Source string
+}
+
+// String returns the version, path and source of the install
+func (i *Install) String() string {
+	version := i.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	return fmt.Sprintf("Blender %s: %s (%s)", version, i.Path, i.Source)
+}
+
+// candidate is a path that may hold a Blender executable
+type candidate struct {
+	path   string
+	source string
+}
+
+// Find returns the Blender executables at the common install paths without running them
+func Find() []*Install {
+	installs := []*Install{}
+	seen := map[string]bool{}
+	for _, c := range candidates() {
+		matches, err := filepath.Glob(c.path)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if !isExecutable(path) {
+				continue
+			}
+			resolved, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				resolved = path
+			}
+			if seen[resolved] {
+				continue
+			}
+			seen[resolved] = true
+			installs = append(installs, &Install{Path: path, Source: c.source})
+		}
+	}
+	return installs
+}
+
+// Detect returns the Blender installs found on this machine with their versions, newest first
+func Detect(ctx context.Context) []*Install {
+	installs := Find()
+	for _, install := range installs {
+		install.Version, _ = Version(ctx, install.Path)
+	}
+	sort.SliceStable(installs, func(i, j int) bool {
+		return release.CompareVersions(installs[i].Version, installs[j].Version) > 0
+	})
+	return installs
+}
+
+// Version runs blender --version at path and returns the version it reports
+func Version(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil && len(out) == 0 {
+		return "", fmt.Errorf("%s --version: %w", filepath.Base(path), err)
+	}
+	matches := versionPattern.FindSubmatch(out)
+	if len(matches) < 2 {
+		return "", fmt.Errorf("%s --version: no version found", filepath.Base(path))
+	}
+	return string(matches[1]), nil
+}
+
+// isExecutable returns true if path is a file that can be run
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".exe") {
+		return true
+	}
+	return fi.Mode()&0111 != 0
+}
+
+// pathCandidates returns name in every folder of PATH
+func pathCandidates(name string) []candidate {
+	list := []candidate{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		list = append(list, candidate{filepath.Join(dir, name), "PATH"})
+	}
+	return list
+}
+
+// steamLibraries returns the steamapps folders listed in a steam libraryfolders.vdf
+func steamLibraries(vdfPath string) []string {
+	data, err := os.ReadFile(vdfPath)
+	if err != nil {
+		return nil
+	}
+	libraries := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.Trim(fields[0], `"`) != "path" {
+			continue
+		}
+		path := strings.ReplaceAll(strings.Trim(fields[1], `"`), `\\`, `\`)
+		libraries = append(libraries, filepath.Join(path, "steamapps"))
+	}
+	return libraries
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+package blender
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// candidates returns the paths Blender is commonly installed to
+func candidates() []candidate {
+	list := pathCandidates("blender")
+	home, _ := os.UserHomeDir()
+	if runtime.GOOS == "darwin" {
+		list = append(list,
+			candidate{"/Applications/Blender.app/Contents/MacOS/Blender", "Applications"},
+			candidate{"/Applications/Blender*.app/Contents/MacOS/Blender", "Applications"},
+			candidate{filepath.Join(home, "Applications/Blender*.app/Contents/MacOS/Blender"), "Applications"},
+			candidate{filepath.Join(home, "Library/Application Support/Steam/steamapps/common/Blender/Blender.app/Contents/MacOS/Blender"), "steam"},
+		)
+		return list
+	}
+
+	list = append(list,
+		candidate{"/usr/bin/blender", "system"},
+		candidate{"/usr/local/bin/blender", "system"},
+		candidate{"/opt/blender*/blender", "/opt"},
+		candidate{"/usr/local/blender*/blender", "/usr/local"},
+		candidate{filepath.Join(home, "blender*/blender"), "home"},
+		candidate{filepath.Join(home, "Applications/blender*/blender"), "home"},
+		candidate{filepath.Join(home, ".local/bin/blender"), "home"},
+		candidate{filepath.Join(home, ".local/share/blender*/blender"), "home"},
+		candidate{"/snap/bin/blender", "snap"},
+		candidate{"/var/lib/flatpak/exports/bin/org.blender.Blender", "flatpak"},
+		candidate{filepath.Join(home, ".local/share/flatpak/exports/bin/org.blender.Blender"), "flatpak"},
+	)
+	steamRoots := []string{
+		filepath.Join(home, ".steam/steam"),
+		filepath.Join(home, ".local/share/Steam"),
+		filepath.Join(home, ".var/app/com.valvesoftware.Steam/.local/share/Steam"),
+	}
+	for _, root := range steamRoots {
+		libraries := append([]string{filepath.Join(root, "steamapps")}, steamLibraries(filepath.Join(root, "steamapps/libraryfolders.vdf"))...)
+		for _, library := range libraries {
+			list = append(list, candidate{filepath.Join(library, "common/Blender/blender"), "steam"})
+		}
+	}
+	return list
+}
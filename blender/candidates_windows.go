@@ -0,0 +1,25 @@
+package blender
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// candidates returns the paths Blender is commonly installed to
+func candidates() []candidate {
+	list := pathCandidates("blender.exe")
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)"} {
+		dir := os.Getenv(env)
+		if dir == "" {
+			continue
+		}
+		list = append(list,
+			candidate{filepath.Join(dir, `Blender Foundation\Blender*\blender.exe`), "Program Files"},
+			candidate{filepath.Join(dir, `Steam\steamapps\common\Blender\blender.exe`), "steam"},
+		)
+		for _, library := range steamLibraries(filepath.Join(dir, `Steam\steamapps\libraryfolders.vdf`)) {
+			list = append(list, candidate{filepath.Join(library, `common\Blender\blender.exe`), "steam"})
+		}
+	}
+	return list
+}
@@ -0,0 +1,47 @@
+package blender
+
+import (
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/release"
+)
+
+// Range is the Blender versions a tool supports. Max covers every release starting with it, so 3 is all of 3.x.
+// An empty Min or Max is unbounded
+type Range struct {
+	Min string
+	Max string
+}
+
+// Contains returns true if version is within the range
+func (r Range) Contains(version string) bool {
+	if r.Min != "" && release.CompareVersions(version, r.Min) < 0 {
+		return false
+	}
+	if r.Max == "" {
+		return true
+	}
+	parts := strings.Split(version, ".")
+	maxParts := strings.Split(r.Max, ".")
+	if len(parts) > len(maxParts) {
+		parts = parts[:len(maxParts)]
+	}
+	return release.CompareVersions(strings.Join(parts, "."), r.Max) <= 0
+}
+
+// String returns the range as text, e.g. 2.80 to 3.x
+func (r Range) String() string {
+	max := r.Max
+	if max != "" {
+		max += ".x"
+	}
+	switch {
+	case r.Min == "" && max == "":
+		return "any version"
+	case r.Min == "":
+		return max + " or older"
+	case max == "":
+		return r.Min + " or newer"
+	}
+	return r.Min + " to " + max
+}
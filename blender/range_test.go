@@ -0,0 +1,49 @@
+package blender
+
+import "testing"
+
+func TestRangeContains(t *testing.T) {
+	blender3 := Range{Min: "2.80", Max: "3"}
+	tests := []struct {
+		r       Range
+		version string
+		want    bool
+	}{
+		{blender3, "2.80", true},
+		{blender3, "2.93.18", true},
+		{blender3, "3.6.2", true},
+		{blender3, "2.79", false},
+		{blender3, "4.0.2", false},
+		{blender3, "", false},
+		{Range{Min: "2.80", Max: "3.6"}, "3.6.9", true},
+		{Range{Min: "2.80", Max: "3.6"}, "3.5", true},
+		{Range{Min: "2.80", Max: "3.6"}, "3.7", false},
+		{Range{Min: "3.0"}, "4.1", true},
+		{Range{Max: "3"}, "2.49", true},
+		{Range{}, "4.2", true},
+	}
+	for _, tt := range tests {
+		got := tt.r.Contains(tt.version)
+		if got != tt.want {
+			t.Errorf("%s contains %q = %t, want %t", tt.r, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	tests := []struct {
+		r    Range
+		want string
+	}{
+		{Range{Min: "2.80", Max: "3"}, "2.80 to 3.x"},
+		{Range{Min: "3.0"}, "3.0 or newer"},
+		{Range{Max: "3.6"}, "3.6.x or older"},
+		{Range{}, "any version"},
+	}
+	for _, tt := range tests {
+		got := tt.r.String()
+		if got != tt.want {
+			t.Errorf("%#v = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
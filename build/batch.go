@@ -0,0 +1,146 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// BatchResult is the outcome of building one zone of a batch
+type BatchResult struct {
+	Zone    string
+	Err     error
+	Elapsed time.Duration
+	// Diagnostics are the problems found in the zone's logs
+	Diagnostics []*Diagnostic
+}
+
+// Status returns ok, failed or cancelled
+func (r *BatchResult) Status() string {
+	if r.Err == nil {
+		return "ok"
+	}
+	if errors.Is(r.Err, context.Canceled) {
+		return "cancelled"
+	}
+	return "failed"
+}
+
+// Batch builds several zones at once
+type Batch struct {
+	// Workers is how many zones build at once, 0 uses the number of CPUs
+	Workers int
+	// MaxBlender is how many Blender instances run at once across all zones, 0 is one
+	MaxBlender int
+	// New returns the builder of a zone
+	New func(zone string) *Builder
+	// OnStart and OnResult are called as each zone starts and finishes, if set. They may be called from several goroutines
+	OnStart  func(zone string)
+	OnResult func(r *BatchResult)
+}
+
+// Run builds zones through a pool of workers, returning a result per zone in the order given.
+// Each zone writes its own logs, cancelling ctx stops every running build
+func (bt *Batch) Run(ctx context.Context, zones []string) []*BatchResult {
+	workers := bt.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(zones) {
+		workers = len(zones)
+	}
+	maxBlender := bt.MaxBlender
+	if maxBlender <= 0 {
+		maxBlender = 1
+	}
+	slots := make(chan struct{}, maxBlender)
+
+	results := make([]*BatchResult, len(zones))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				// the send can win the dispatch select after the batch was cancelled
+				if ctx.Err() != nil {
+					results[index] = &BatchResult{Zone: zones[index], Err: ctx.Err()}
+					continue
+				}
+				results[index] = bt.build(ctx, zones[index], slots)
+			}
+		}()
+	}
+	for i := range zones {
+		if ctx.Err() != nil {
+			results[i] = &BatchResult{Zone: zones[i], Err: ctx.Err()}
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i] = &BatchResult{Zone: zones[i], Err: ctx.Err()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func (bt *Batch) build(ctx context.Context, zone string, slots chan struct{}) *BatchResult {
+	if bt.OnStart != nil {
+		bt.OnStart(zone)
+	}
+	b := bt.New(zone)
+	b.BlenderSlots = slots
+	start := time.Now()
+	err := b.Run(ctx)
+	r := &BatchResult{Zone: zone, Err: err, Elapsed: time.Since(start), Diagnostics: b.Diagnostics}
+	if bt.OnResult != nil {
+		bt.OnResult(r)
+	}
+	return r
+}
+
+// WriteSummary writes a table of results to w
+func WriteSummary(w io.Writer, results []*BatchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "zone\tresult\tduration\tproblems\n")
+	okCount := 0
+	for _, r := range results {
+		if r.Err == nil {
+			okCount++
+		}
+		problem := ""
+		if r.Err != nil {
+			problem = r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Zone, r.Status(), r.Elapsed.Round(100*time.Millisecond), problem)
+	}
+	fmt.Fprintf(tw, "\n%d of %d zones built\n", okCount, len(results))
+	return tw.Flush()
+}
+
+// WriteSummaryLog writes the results of a batch to batch.log in currentPath
+func WriteSummaryLog(currentPath string, version string, results []*BatchResult) error {
+	w, err := os.Create(filepath.Join(currentPath, "batch.log"))
+	if err != nil {
+		return fmt.Errorf("create batch.log: %w", err)
+	}
+	defer w.Close()
+	fmt.Fprintf(w, "Batch build from eqgzi-manager v%s at %s\n\n", strings.TrimSpace(version), time.Now().Format(time.RFC3339))
+	err = WriteSummary(w, results)
+	if err != nil {
+		return fmt.Errorf("write batch.log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package build
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bt := &Batch{
+		Workers: 2,
+		New: func(zone string) *Builder {
+			t.Errorf("built %s after the batch was cancelled", zone)
+			return nil
+		},
+	}
+	zones := []string{"zonea", "zoneb", "zonec", "zoned"}
+	results := bt.Run(ctx, zones)
+	if len(results) != len(zones) {
+		t.Fatalf("results = %d, want %d", len(results), len(zones))
+	}
+	for i, r := range results {
+		if r.Zone != zones[i] || r.Status() != "cancelled" {
+			t.Errorf("result %d = %s %s, want %s cancelled", i, r.Zone, r.Status(), zones[i])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/xackery/eqgzi-manager/blender"
+	"github.com/xackery/eqgzi-manager/tool"
+)
+
+// hasBlender returns true if a step of the pipeline runs Blender
+func (p *Pipeline) hasBlender() bool {
+	for _, step := range p.Steps {
+		if step.IsBlender {
+			return true
+		}
+	}
+	return false
+}
+
+// BlenderRange returns the Blender versions the zone's eqgzi version supports
+func (b *Builder) BlenderRange() blender.Range {
+	version := b.EQGZIVersion
+	if version == "" {
+		versions, _ := tool.New(b.CurrentPath).Versions(tool.EQGZI)
+		if len(versions) > 0 {
+			version = versions[0]
+		}
+	}
+	return tool.BlenderRange(version)
+}
+
+// CheckBlender returns the version of the zone's Blender, and an error if the zone's eqgzi doesn't support it.
+// The version is empty if Blender couldn't be queried
+func (b *Builder) CheckBlender(ctx context.Context) (string, error) {
+	path, err := exec.LookPath(b.BlenderExecutable())
+	if err != nil {
+		return "", fmt.Errorf("blender not found at %s, set the blender path", b.BlenderExecutable())
+	}
+	version, err := blender.Version(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	r := b.BlenderRange()
+	if !r.Contains(version) {
+		return version, fmt.Errorf("blender %s is not supported by eqgzi %s, which needs blender %s", version, valueOr(b.EQGZIVersion, b.newestEQGZIVersion()), r)
+	}
+	return version, nil
+}
+
+// checkBlender stops a build whose Blender the zone's eqgzi doesn't support, unless unsupported versions are allowed
+func (b *Builder) checkBlender(ctx context.Context) error {
+	version, err := b.CheckBlender(ctx)
+	if version == "" {
+		b.logf("Skipped blender version check: %s", err)
+		return nil
+	}
+	b.blenderVersion = version
+	if err == nil {
+		return nil
+	}
+	if b.IsUnsupportedBlenderAllowed {
+		b.logf("Warning: %s", err)
+		return nil
+	}
+	return fmt.Errorf("%w. Set blender_path to a supported blender, or allow_unsupported_blender to build anyway", err)
+}
@@ -0,0 +1,453 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/launch"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+// Builder runs the convert and copy scripts of a zone without any GUI
+type Builder struct {
+	CurrentPath  string
+	Zone         string
+	BlenderPath  string
+	EQPath       string
+	ServerPath   string
+	IsEQCopy     bool
+	IsServerCopy bool
+	// EQGZIVersion is the installed eqgzi version to build with, empty uses the newest
+	EQGZIVersion string
+	// LanternVersion is the installed LanternExtractor version to import with, empty uses the newest
+	LanternVersion string
+	// Version is written to the head of every log
+	Version string
+	// DefaultFiles are the files a new zone is created with. A zone .bat script
+	// that differs from its default is run instead of the native pipeline
+	DefaultFiles map[string][]byte
+	// Logf is called with status messages, if set
+	Logf func(format string, a ...interface{})
+	// OnEvent is called as steps start, progress, produce files and finish, if set
+	OnEvent func(e Event)
+	// Diagnostics are the problems found in logs since the last Run
+	Diagnostics []*Diagnostic
+	// IsForceRebuild runs every step, even those whose inputs are unchanged since they last succeeded
+	IsForceRebuild bool
+	// Reused are the steps skipped by the last run since their inputs were unchanged
+	Reused []string
+	// HistoryLimit is how many runs Run keeps in the zone's history, 0 uses DefaultHistoryLimit
+	HistoryLimit int
+	// WinePath runs windows tools through Wine when not on windows, empty runs them directly
+	WinePath string
+	// IsUnsupportedBlenderAllowed builds with a Blender outside the versions eqgzi supports, with a warning
+	IsUnsupportedBlenderAllowed bool
+	// BlenderSlots, if set, limits how many Blender steps run at once across the builders sharing it
+	BlenderSlots chan struct{}
+	// Manifest is the zone.toml of the zone, nil if it has none
+	Manifest    *zone.Manifest
+	manifestErr error
+	// stepIndex and stepCount position the running step within the whole run
+	stepIndex int
+	stepCount int
+	// runSteps, runLogs and blenderVersion are recorded in the history of Run
+	runSteps       []RunStep
+	runLogs        []string
+	blenderVersion string
+}
+
+// stage is a pipeline and the log it writes to
+type stage struct {
+	pipeline *Pipeline
+	logName  string
+}
+
+// New creates a new builder for zone based on cfg and the zone's manifest
+func New(cfg *config.Config, currentPath string, zone string) *Builder {
+	b := &Builder{
+		CurrentPath:    currentPath,
+		Zone:           zone,
+		BlenderPath:    cfg.BlenderPath,
+		EQPath:         cfg.EQPath,
+		ServerPath:     cfg.ServerPath,
+		IsEQCopy:       cfg.IsEQCopy,
+		IsServerCopy:   cfg.IsServerCopy,
+		EQGZIVersion:   cfg.EQGZIVersionFor(zone),
+		LanternVersion: cfg.LanternVersion,
+		HistoryLimit:   cfg.HistoryLimit,
+	}
+	b.WinePath = cfg.WinePath
+	b.IsUnsupportedBlenderAllowed = cfg.IsUnsupportedBlenderAllowed
+	b.loadManifest(cfg.ZoneEQGZIPins[zone])
+	return b
+}
+
+// Run converts the zone, then copies it to EQ and server paths if enabled, recording the run in the zone's history.
+// Cancelling ctx stops the running step and every process it started
+func (b *Builder) Run(ctx context.Context) error {
+	start := time.Now()
+	b.runSteps = nil
+	b.runLogs = nil
+	b.blenderVersion = ""
+	err := b.run(ctx)
+	saveErr := b.saveRun(start, err)
+	if saveErr != nil {
+		b.logf("Failed to record build history: %s", saveErr)
+	}
+	return err
+}
+
+func (b *Builder) run(ctx context.Context) error {
+	b.Diagnostics = nil
+	b.Reused = nil
+	err := b.checkManifest()
+	if err != nil {
+		return err
+	}
+	err = b.checkTools()
+	if err != nil {
+		return err
+	}
+	b.logf("Converting %s", b.Zone)
+	p, err := b.ZonePipeline()
+	if err != nil {
+		return err
+	}
+	if p.hasBlender() {
+		err = b.checkBlender(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	stages := []stage{{p, "convert.log"}}
+	extra := b.CopyExtraPipeline()
+	if len(extra.Steps) > 0 {
+		stages = append(stages, stage{extra, "copy_extra.log"})
+	}
+	if b.IsEQCopy {
+		stages = append(stages, stage{b.CopyEQPipeline(), "copy_eq.log"})
+	}
+	if b.IsServerCopy {
+		stages = append(stages, stage{b.CopyServerPipeline(), "copy_server.log"})
+	}
+
+	b.stepIndex = 0
+	b.stepCount = 0
+	for _, s := range stages {
+		b.stepCount += len(s.pipeline.Steps)
+	}
+	defer func() {
+		b.stepCount = 0
+	}()
+
+	for _, s := range stages {
+		err = b.RunPipeline(ctx, s.pipeline, s.logName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Convert runs the convert pipeline for the zone
+func (b *Builder) Convert(ctx context.Context) error {
+	b.Reused = nil
+	err := b.checkManifest()
+	if err != nil {
+		return err
+	}
+	err = b.checkTools()
+	if err != nil {
+		return err
+	}
+	b.logf("Converting %s", b.Zone)
+	p, err := b.ZonePipeline()
+	if err != nil {
+		return err
+	}
+	if p.hasBlender() {
+		err = b.checkBlender(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return b.RunPipeline(ctx, p, "convert.log")
+}
+
+// CopyEQ runs the copy to EverQuest pipeline for the zone
+func (b *Builder) CopyEQ(ctx context.Context) error {
+	err := b.checkManifest()
+	if err != nil {
+		return err
+	}
+	return b.RunPipeline(ctx, b.CopyEQPipeline(), "copy_eq.log")
+}
+
+// CopyServer runs the copy to server pipeline for the zone
+func (b *Builder) CopyServer(ctx context.Context) error {
+	err := b.checkManifest()
+	if err != nil {
+		return err
+	}
+	return b.RunPipeline(ctx, b.CopyServerPipeline(), "copy_server.log")
+}
+
+// Env returns the environment passed to zone .bat scripts
+func (b *Builder) Env() []string {
+	return []string{
+		fmt.Sprintf(`PATH=%s;%s`, b.BlenderPath, b.ToolsPath()),
+		fmt.Sprintf(`EQPATH=%s`, strings.ReplaceAll(b.EQPath, "/", `\`)),
+		fmt.Sprintf(`EQGZI=%s\`, b.ToolsPath()),
+		fmt.Sprintf(`ZONE=%s`, b.Zone),
+		fmt.Sprintf(`EQSERVERPATH=%s`, strings.ReplaceAll(b.ServerPath, "/", `\`)),
+		fmt.Sprintf(`BLENDERPATH=%s`, b.BlenderPath),
+		fmt.Sprintf(`ZONELONGNAME=%s`, b.longName()),
+		fmt.Sprintf(`ZONEID=%d`, b.zoneID()),
+	}
+}
+
+// nativeEnv returns the environment passed to pipeline commands
+func (b *Builder) nativeEnv() []string {
+	env := []string{}
+	for _, line := range os.Environ() {
+		if strings.HasPrefix(strings.ToUpper(line), "PATH=") {
+			continue
+		}
+		env = append(env, line)
+	}
+	paths := []string{}
+	if b.BlenderPath != "" {
+		paths = append(paths, b.BlenderPath)
+	}
+	paths = append(paths, b.ToolsPath(), os.Getenv("PATH"))
+	return append(env,
+		fmt.Sprintf("PATH=%s", strings.Join(paths, string(os.PathListSeparator))),
+		fmt.Sprintf("EQPATH=%s", filepath.FromSlash(b.EQPath)),
+		fmt.Sprintf("EQGZI=%s%c", b.ToolsPath(), os.PathSeparator),
+		fmt.Sprintf("ZONE=%s", b.Zone),
+		fmt.Sprintf("EQSERVERPATH=%s", filepath.FromSlash(b.ServerPath)),
+		fmt.Sprintf("BLENDERPATH=%s", b.BlenderPath),
+		fmt.Sprintf("ZONELONGNAME=%s", b.longName()),
+		fmt.Sprintf("ZONEID=%d", b.zoneID()),
+	)
+}
+
+// RunPipeline runs each step of p in order, writing their output to logName in the zone folder
+func (b *Builder) RunPipeline(ctx context.Context, p *Pipeline, logName string) error {
+	if b.stepCount == 0 {
+		b.stepIndex = 0
+		b.stepCount = len(p.Steps)
+		defer func() {
+			b.stepCount = 0
+		}()
+	}
+
+	out, err := b.newOutputLog(logName)
+	if err != nil {
+		return err
+	}
+	b.runLogs = append(b.runLogs, logName)
+	defer func() {
+		out.Close()
+		b.Diagnostics = append(b.Diagnostics, out.diagnostics...)
+	}()
+
+	var cache *stepCache
+	if p.IsCached {
+		cache = b.loadCache()
+		if b.IsForceRebuild {
+			out.writeString("Forcing a full rebuild\n")
+			cache.Steps = map[string]*stepRecord{}
+		}
+	}
+	key := ""
+
+	for _, step := range p.Steps {
+		out.step = step.Name
+		out.isScript = strings.HasSuffix(step.Name, ".bat")
+		if cache != nil {
+			key, err = b.stepKey(key, step)
+			if err != nil {
+				out.writeString(fmt.Sprintf("\nNot reusing steps from %s on: %s\n", step.Name, err))
+				cache = nil
+			}
+		}
+		if cache != nil {
+			record := b.reusable(cache, step, key)
+			if record != nil {
+				b.emit(Event{Type: EventStepStarted, Step: step.Name})
+				out.writeString(fmt.Sprintf("\nReused step %s, inputs unchanged since %s\n", step.Name, record.Time.Format(time.RFC3339)))
+				b.emitArtifacts(step)
+				b.emit(Event{Type: EventStepFinished, Step: step.Name, Reused: true})
+				b.Reused = append(b.Reused, step.Name)
+				b.runSteps = append(b.runSteps, RunStep{Name: step.Name, Reused: true})
+				b.stepIndex++
+				continue
+			}
+			delete(cache.Steps, step.Name)
+		}
+		if step.Name == "blender" || step.Name == "convert.bat" {
+			out.isScriptStepExpected = true
+		}
+		start := time.Now()
+		b.emit(Event{Type: EventStepStarted, Step: step.Name})
+		if ctx.Err() == nil {
+			err = b.runStep(ctx, step, out)
+		}
+		if ctx.Err() != nil && err == nil {
+			err = ctx.Err()
+		}
+		elapsed := time.Since(start)
+		out.writeString(fmt.Sprintf("Finished step %s in %s\n", step.Name, elapsed.Round(time.Millisecond)))
+		if err == nil {
+			b.emitArtifacts(step)
+		}
+		b.emit(Event{Type: EventStepFinished, Step: step.Name, Elapsed: elapsed, Err: err})
+		runStep := RunStep{Name: step.Name, Duration: elapsed}
+		if err != nil {
+			runStep.Error = err.Error()
+		}
+		b.runSteps = append(b.runSteps, runStep)
+		b.stepIndex++
+		if ctx.Err() != nil {
+			out.writeString(fmt.Sprintf("\nBuild cancelled during %s\n", step.Name))
+			return fmt.Errorf("cancelled during %s: %w", step.Name, ctx.Err())
+		}
+		if err != nil {
+			out.writeString(fmt.Sprintf("failed during %s: %s\n", step.Name, err))
+			failure := Failure(out.diagnostics)
+			if failure != nil {
+				return fmt.Errorf("failed during %s: %s", step.Name, failure)
+			}
+			return fmt.Errorf("failed during %s: %w", step.Name, err)
+		}
+		// later steps only add noise once a step reported an error
+		if Failure(out.diagnostics) != nil {
+			break
+		}
+		if cache != nil {
+			err = b.record(cache, step, key, elapsed)
+			if err != nil {
+				out.writeString(fmt.Sprintf("Failed to record step %s: %s\n", step.Name, err))
+			}
+		}
+	}
+	return out.Result()
+}
+
+func (b *Builder) runStep(ctx context.Context, step *Step, out *outputLog) error {
+	err := out.writeString(fmt.Sprintf("\nRunning step %s\n", step.Name))
+	if err != nil {
+		return fmt.Errorf("write to %s: %w", out.name, err)
+	}
+
+	if step.IsBlender && b.BlenderSlots != nil {
+		select {
+		case b.BlenderSlots <- struct{}{}:
+		default:
+			out.writeString("Waiting for a free Blender slot\n")
+			select {
+			case b.BlenderSlots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		defer func() {
+			<-b.BlenderSlots
+		}()
+	}
+
+	if step.Command != "" {
+		cmd := b.createCommand(true, step.Command, step.Args...)
+		cmd.Dir = step.Dir
+		if !filepath.IsAbs(cmd.Dir) {
+			cmd.Dir = filepath.Join(b.zonePath(""), step.Dir)
+		}
+		cmd.Env = step.Env
+		if cmd.Env == nil {
+			cmd.Env = b.nativeEnv()
+		}
+
+		reader, err := startCommand(cmd)
+		if err != nil {
+			return fmt.Errorf("start %s: %w", filepath.Base(step.Command), b.launcher().Error(step.Command, err))
+		}
+
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				err := killCommand(cmd)
+				if err != nil {
+					b.logf("Failed to stop %s: %s", step.Name, err)
+				}
+			case <-done:
+			}
+		}()
+		err = b.processOutput(reader, out)
+		if err != nil {
+			cmd.Wait()
+			close(done)
+			return err
+		}
+		err = cmd.Wait()
+		close(done)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(step.Command), err)
+		}
+	}
+
+	if step.Action != nil {
+		err = step.Action(b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startCommand(cmd *exec.Cmd) (io.Reader, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdoutpipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stderrpipe: %w", err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	return io.MultiReader(stdout, stderr), nil
+}
+
+// launcher returns the launcher that starts the builder's commands
+func (b *Builder) launcher() *launch.Launcher {
+	return &launch.Launcher{WinePath: b.WinePath}
+}
+
+func (b *Builder) logf(format string, a ...interface{}) {
+	if b.Logf == nil {
+		return
+	}
+	b.Logf(format, a...)
+}
+
+// emitArtifacts reports the outputs of step that exist
+func (b *Builder) emitArtifacts(step *Step) {
+	for _, output := range step.Outputs {
+		fi, err := os.Stat(b.zonePath(output))
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		b.emit(Event{Type: EventArtifact, Step: step.Name, Path: output, Size: fi.Size()})
+	}
+}
@@ -0,0 +1,186 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheName is the file in a zone's .build folder that records the fingerprints of successful steps
+const cacheName = "steps.json"
+
+// stepCache records the fingerprint and outputs of each step that last succeeded
+type stepCache struct {
+	path  string
+	Steps map[string]*stepRecord `json:"steps"`
+}
+
+// stepRecord is the fingerprint a step succeeded with and the files it left behind
+type stepRecord struct {
+	Key      string               `json:"key"`
+	Time     time.Time            `json:"time"`
+	Outputs  map[string]fileState `json:"outputs"`
+	Duration time.Duration        `json:"duration"`
+}
+
+// fileState is the size and sha256 of a file
+type fileState struct {
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+}
+
+// buildPath returns the zone's .build folder, where build records are kept
+func (b *Builder) buildPath(format string, a ...interface{}) string {
+	return b.zonePath(filepath.Join(".build", fmt.Sprintf(format, a...)))
+}
+
+// loadCache reads the step records of the zone, a missing or unreadable record starts empty
+func (b *Builder) loadCache() *stepCache {
+	c := &stepCache{path: b.buildPath(cacheName), Steps: map[string]*stepRecord{}}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	err = json.Unmarshal(data, c)
+	if err != nil || c.Steps == nil {
+		b.logf("Ignoring unreadable %s, rebuilding every step", cacheName)
+		c.Steps = map[string]*stepRecord{}
+	}
+	return c
+}
+
+// save writes the step records
+func (c *stepCache) save() error {
+	err := os.MkdirAll(filepath.Dir(c.path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("mkdir .build: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", cacheName, err)
+	}
+	err = os.WriteFile(c.path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", cacheName, err)
+	}
+	return nil
+}
+
+// reusable returns the record of step if it succeeded with key and its outputs are unchanged since
+func (b *Builder) reusable(c *stepCache, step *Step, key string) *stepRecord {
+	record, ok := c.Steps[step.Name]
+	if !ok || record.Key != key {
+		return nil
+	}
+	for path, state := range record.Outputs {
+		current, err := hashFile(b.zonePath(path))
+		if err != nil || current != state {
+			return nil
+		}
+	}
+	return record
+}
+
+// record stores that step succeeded with key, along with the state of its outputs
+func (b *Builder) record(c *stepCache, step *Step, key string, duration time.Duration) error {
+	record := &stepRecord{Key: key, Time: time.Now(), Outputs: map[string]fileState{}, Duration: duration}
+	for _, output := range step.Outputs {
+		state, err := hashFile(b.zonePath(output))
+		if err != nil {
+			continue
+		}
+		record.Outputs[output] = state
+	}
+	c.Steps[step.Name] = record
+	return c.save()
+}
+
+// stepKey fingerprints step from the key of the step before it, its command, arguments, tool and inputs
+func (b *Builder) stepKey(previous string, step *Step) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "previous %s\nstep %s\ncommand %s\nargs %q\ndir %s\n", previous, step.Name, step.Command, step.Args, step.Dir)
+	if step.Command != "" {
+		path, err := exec.LookPath(step.Command)
+		if err == nil {
+			fi, err := os.Stat(path)
+			if err == nil {
+				fmt.Fprintf(h, "tool %s %d %d\n", path, fi.Size(), fi.ModTime().UnixNano())
+			}
+		}
+	}
+	paths, err := b.inputFiles(step)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range paths {
+		state, err := hashFile(path)
+		if err != nil {
+			return "", fmt.Errorf("hash %s: %w", filepath.Base(path), err)
+		}
+		fmt.Fprintf(h, "input %s %d %s\n", path, state.Size, state.Hash)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// inputFiles returns the files matched by the inputs of step, folders are walked
+func (b *Builder) inputFiles(step *Step) ([]string, error) {
+	paths := map[string]bool{}
+	for _, input := range step.Inputs {
+		pattern := input
+		if !filepath.IsAbs(pattern) {
+			pattern = b.zonePath(input)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("glob %s: %w", input, err)
+		}
+		for _, match := range matches {
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					if strings.HasPrefix(d.Name(), ".") && path != match {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				paths[path] = true
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("walk %s: %w", input, err)
+			}
+		}
+	}
+	sorted := []string{}
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// hashFile returns the size and sha256 of the file at path
+func hashFile(path string) (fileState, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	defer r.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{Size: size, Hash: hex.EncodeToString(h.Sum(nil))}, nil
+}
@@ -0,0 +1,148 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newCacheZone returns a builder for a zone holding a .blend and a texture beside it and in texture/
+func newCacheZone(t *testing.T) *Builder {
+	t.Helper()
+	b := &Builder{CurrentPath: t.TempDir(), Zone: "testzone"}
+	for _, path := range []string{"testzone.blend", "wood.png", "texture/floor.png"} {
+		writeZoneFile(t, b, path, path)
+	}
+	return b
+}
+
+func writeZoneFile(t *testing.T, b *Builder, path string, data string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(b.zonePath(path)), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(b.zonePath(path), []byte(data), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHelperBlender stands in for Blender running convert.py when run by runConvert:
+// it prints the steps convert.py reports and exports only the glTF
+func TestHelperBlender(t *testing.T) {
+	if os.Getenv("BUILD_TEST_BLENDER") == "" {
+		return
+	}
+	for i := 1; i <= scriptStepCount; i++ {
+		fmt.Printf("Step %d: converting\n", i)
+	}
+	err := os.MkdirAll("cache", os.ModePerm)
+	if err == nil {
+		err = os.WriteFile("cache/testzone.gltf", []byte("gltf"), 0644)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// runConvert runs the convert pipeline with Blender replaced by TestHelperBlender and the other tools
+// by writing their outputs, and returns the steps that ran rather than being reused
+func runConvert(t *testing.T, b *Builder) []string {
+	t.Helper()
+	ran := []string{}
+	p := b.ConvertPipeline()
+	for _, step := range p.Steps {
+		name := step.Name
+		outputs := step.Outputs
+		step.Command = ""
+		step.Args = nil
+		if name == "blender" {
+			step.Command = os.Args[0]
+			step.Args = []string{"-test.run=TestHelperBlender"}
+			step.Env = append(os.Environ(), "BUILD_TEST_BLENDER=1")
+			outputs = nil
+		}
+		step.Action = func(b *Builder) error {
+			ran = append(ran, name)
+			for _, output := range outputs {
+				writeZoneFile(t, b, output, name+" "+output)
+			}
+			return nil
+		}
+	}
+	err := b.RunPipeline(context.Background(), p, "convert.log")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return ran
+}
+
+func TestIncrementalBuild(t *testing.T) {
+	everyStep := []string{"blender", "eqgzi", "azone", "awater"}
+	tests := []struct {
+		name   string
+		change func(t *testing.T, b *Builder)
+		want   []string
+	}{
+		{"unchanged", func(t *testing.T, b *Builder) {}, nil},
+		{"blend changed", func(t *testing.T, b *Builder) {
+			writeZoneFile(t, b, "testzone.blend", "moved a wall")
+		}, everyStep},
+		{"texture changed", func(t *testing.T, b *Builder) {
+			writeZoneFile(t, b, "wood.png", "darker wood")
+		}, []string{"eqgzi", "azone", "awater"}},
+		{"texture folder changed", func(t *testing.T, b *Builder) {
+			writeZoneFile(t, b, "texture/floor.png", "new floor")
+		}, []string{"eqgzi", "azone", "awater"}},
+		{"texture added", func(t *testing.T, b *Builder) {
+			writeZoneFile(t, b, "texture/wall.png", "wall")
+		}, []string{"eqgzi", "azone", "awater"}},
+		{"gltf deleted", func(t *testing.T, b *Builder) {
+			os.Remove(b.zonePath("cache/testzone.gltf"))
+		}, []string{"blender"}},
+		{"gltf edited", func(t *testing.T, b *Builder) {
+			writeZoneFile(t, b, "cache/testzone.gltf", "edited")
+		}, []string{"blender"}},
+		{"eqg deleted", func(t *testing.T, b *Builder) {
+			os.Remove(b.zonePath("out/testzone.eqg"))
+		}, []string{"eqgzi"}},
+		{"forced", func(t *testing.T, b *Builder) {
+			b.IsForceRebuild = true
+		}, everyStep},
+		{"steps.json unreadable", func(t *testing.T, b *Builder) {
+			writeZoneFile(t, b, ".build/steps.json", "{")
+		}, everyStep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCacheZone(t)
+			got := runConvert(t, b)
+			if strings.Join(got, " ") != strings.Join(everyStep, " ") {
+				t.Fatalf("first build ran %v, want %v", got, everyStep)
+			}
+			tt.change(t, b)
+			got = runConvert(t, b)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("ran %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForcedBuildRecords(t *testing.T) {
+	b := newCacheZone(t)
+	runConvert(t, b)
+	b.IsForceRebuild = true
+	runConvert(t, b)
+	b.IsForceRebuild = false
+	got := runConvert(t, b)
+	if len(got) > 0 {
+		t.Errorf("build after a forced build ran %v, want every step reused", got)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package build
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// createCommand returns a command running name with arg, through Wine for a windows program when WinePath is set
+func (b *Builder) createCommand(isHidden bool, name string, arg ...string) *exec.Cmd {
+	cmd := b.launcher().Command(name, arg...)
+	// a process group lets killCommand reach children of the command
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return cmd
+}
+
+// killCommand terminates cmd and every process it started
+func killCommand(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	if err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package build
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// createCommand returns a command running name with arg
+func (b *Builder) createCommand(isHidden bool, name string, arg ...string) *exec.Cmd {
+	cmd := b.launcher().Command(name, arg...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: isHidden}
+	return cmd
+}
+
+// killCommand terminates cmd and every process it started
+func killCommand(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	kill := exec.Command("taskkill", "/T", "/F", "/PID", fmt.Sprintf("%d", cmd.Process.Pid))
+	kill.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	err := kill.Run()
+	if err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity is how serious a diagnostic is
+type Severity int
+
+// Severities, from least to most serious
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+// String returns the severity name
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Rule matches log lines to a known problem
+type Rule struct {
+	Pattern  *regexp.Regexp
+	Severity Severity
+	// Step is the pipeline step the rule applies to, empty for every step.
+	// Customized .bat scripts run every step, so all rules apply to them
+	Step string
+	// IsNextLine reports the line after the match, which holds the actual message
+	IsNextLine bool
+	// MinScriptStep is the convert.py "Step N" the rule applies from while convert.py runs.
+	// Earlier matching lines match no rule at all
+	MinScriptStep int
+	Explanation   string
+	Fix           string
+}
+
+// Diagnostic is a problem found in a build log
+type Diagnostic struct {
+	Rule *Rule
+	Log  string
+	// Line is the line number in Log
+	Line int
+	Step string
+	Text string
+}
+
+// String returns the diagnostic as log:line text (explanation)
+func (d *Diagnostic) String() string {
+	text := fmt.Sprintf("%s:%d %s", d.Log, d.Line, strings.TrimSpace(d.Text))
+	if d.Rule.Explanation != "" {
+		text += fmt.Sprintf(" (%s)", d.Rule.Explanation)
+	}
+	return text
+}
+
+// Rules are checked in order against every log line, the first match wins.
+// Earlier rules are considered a better explanation of a failed build
+var Rules = []*Rule{
+	{
+		Pattern:     regexp.MustCompile(`^KeyError:.*(not found.*bpy_prop_collection|bpy_prop_collection.*not found)`),
+		Severity:    SeverityError,
+		Step:        "blender",
+		Explanation: "an image texture is not properly exported",
+		Fix:         "Check every material's image texture node points to an image file in the zone folder",
+	},
+	{
+		Pattern:     regexp.MustCompile(`^KeyError:`),
+		Severity:    SeverityError,
+		Step:        "blender",
+		Explanation: "convert.py looked up something that does not exist",
+		Fix:         "Check the Blender version is supported and the zone uses the base.blend layout",
+	},
+	{
+		Pattern:     regexp.MustCompile(`GPUTexture: Blender Texture Not Loaded!`),
+		Severity:    SeverityError,
+		Step:        "blender",
+		Explanation: "a reference to a texture in blender is broken",
+		Fix:         "Find the pink material in Blender and relink its image",
+	},
+	{
+		Pattern:     regexp.MustCompile(`failed to find.*in current path, defined`),
+		Severity:    SeverityError,
+		Explanation: "texture missing",
+		Fix:         "Copy the texture into the zone folder next to the .blend",
+	},
+	{
+		Pattern:     regexp.MustCompile(`PermissionError: \[Errno 13\] Permission denied: '\.'`),
+		Severity:    SeverityError,
+		Step:        "blender",
+		Explanation: "This is usually caused by an embedded image",
+		Fix:         "Unpack embedded images in Blender with File > External Data > Unpack Resources",
+	},
+	{
+		Pattern:     regexp.MustCompile(`^ModuleNotFoundError:`),
+		Severity:    SeverityError,
+		Step:        "blender",
+		Explanation: "Blender's python is missing a module convert.py needs",
+		Fix:         "Use a supported Blender install rather than a system python build",
+	},
+	{
+		Pattern:       regexp.MustCompile(`main_cmd error:`),
+		Severity:      SeverityError,
+		IsNextLine:    true,
+		MinScriptStep: 7,
+		Explanation:   "eqgzi failed",
+	},
+	{
+		Pattern:     regexp.MustCompile(`missing.*not copying`),
+		Severity:    SeverityError,
+		Explanation: "a file referenced by the zone was not found",
+		Fix:         "Copy the file into the zone folder",
+	},
+	{
+		Pattern:  regexp.MustCompile(`error`),
+		Severity: SeverityError,
+	},
+	{
+		Pattern:  regexp.MustCompile(`^Warning:|\bwarning:`),
+		Severity: SeverityWarning,
+	},
+}
+
+// match returns the first rule matching line during the log's current step
+func (o *outputLog) match(line string) *Rule {
+	isConvertRunning := o.isScript || o.step == "blender"
+	for _, rule := range Rules {
+		if rule.Step != "" && rule.Step != o.step && !o.isScript {
+			continue
+		}
+		if !rule.Pattern.MatchString(line) {
+			continue
+		}
+		if isConvertRunning && o.scriptStep < rule.MinScriptStep {
+			return nil
+		}
+		return rule
+	}
+	return nil
+}
+
+// ruleIndex returns the position of rule in Rules, used to rank diagnostics
+func ruleIndex(rule *Rule) int {
+	for i, r := range Rules {
+		if r == rule {
+			return i
+		}
+	}
+	return len(Rules)
+}
+
+// Failure returns the error diagnostic that best explains a failed build, or nil
+func Failure(diagnostics []*Diagnostic) *Diagnostic {
+	var best *Diagnostic
+	for _, d := range diagnostics {
+		if d.Rule.Severity != SeverityError {
+			continue
+		}
+		if best == nil || ruleIndex(d.Rule) < ruleIndex(best.Rule) {
+			best = d
+		}
+	}
+	return best
+}
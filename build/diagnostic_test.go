@@ -0,0 +1,104 @@
+package build
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// scanLog runs lines through the output scanner of a step, returning the diagnostics found
+func scanLog(t *testing.T, step string, lines []string) []*Diagnostic {
+	t.Helper()
+	b := &Builder{CurrentPath: t.TempDir(), Zone: "testzone"}
+	err := os.MkdirAll(b.zonePath(""), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := b.newOutputLog("convert.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	out.step = step
+	out.isScript = strings.HasSuffix(step, ".bat")
+	err = b.processOutput(strings.NewReader(strings.Join(lines, "\n")+"\n"), out)
+	if err != nil {
+		t.Fatalf("process output: %v", err)
+	}
+	return out.diagnostics
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		step  string
+		lines []string
+		// want is the text of each diagnostic found, and wantLine the line of the first.
+		// Line 1 of a log is the header the manager writes
+		want     []string
+		wantLine int
+	}{
+		{"main_cmd before step 7", "blender", []string{"Step 5: exporting", "main_cmd error:", "bad mesh"}, nil, 0},
+		{"main_cmd in a script before step 7", "convert.bat", []string{"Step 6: exporting", "main_cmd error:", "bad mesh"}, nil, 0},
+		{"main_cmd from step 7", "blender", []string{"Step 7: packing", "main_cmd error:", "bad mesh"}, []string{"bad mesh"}, 4},
+		{"main_cmd in a script from step 7", "convert.bat", []string{"Step 7: packing", "main_cmd error:", "bad mesh"}, []string{"bad mesh"}, 4},
+		{"main_cmd from eqgzi", "eqgzi", []string{"main_cmd error:", "open testzone.gltf: not found"}, []string{"open testzone.gltf: not found"}, 3},
+		{"next line after the last line", "eqgzi", []string{"main_cmd error:"}, nil, 0},
+		{"key error", "blender", []string{"Step 2: materials", "KeyError: 'bpy_prop_collection[key]: key \"wood\" not found'"}, []string{"KeyError: 'bpy_prop_collection[key]: key \"wood\" not found'"}, 3},
+		{"step rule in another step", "eqgzi", []string{"KeyError: 'wood'"}, nil, 0},
+		{"step rule in a script", "convert.bat", []string{"KeyError: 'wood'"}, []string{"KeyError: 'wood'"}, 2},
+		{"generic error", "azone", []string{"loading zone", "fatal error reading eqg"}, []string{"fatal error reading eqg"}, 3},
+		{"warning", "awater", []string{"Warning: region 2 is untagged"}, []string{"Warning: region 2 is untagged"}, 2},
+		{"clean", "blender", []string{"Step 1: starting", "Step 7: done"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := scanLog(t, tt.step, tt.lines)
+			if len(diagnostics) != len(tt.want) {
+				t.Fatalf("diagnostics = %v, want %q", diagnostics, tt.want)
+			}
+			for i, d := range diagnostics {
+				if strings.TrimSpace(d.Text) != tt.want[i] {
+					t.Errorf("diagnostic %d = %q, want %q", i, strings.TrimSpace(d.Text), tt.want[i])
+				}
+			}
+			if len(diagnostics) > 0 && diagnostics[0].Line != tt.wantLine {
+				t.Errorf("line = %d, want %d", diagnostics[0].Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     string
+		lines    []string
+		isFailed bool
+		// want is the explanation of the failure
+		want string
+	}{
+		{"key error over generic error", "blender", []string{"an error occurred", "KeyError: 'wood'"}, true, "convert.py looked up something that does not exist"},
+		{"texture key error over key error", "blender", []string{"KeyError: 'wood'", "KeyError: 'bpy_prop_collection[key]: key \"wood\" not found'"}, true, "an image texture is not properly exported"},
+		{"main_cmd over generic error", "eqgzi", []string{"an error occurred", "main_cmd error:", "bad mesh"}, true, "eqgzi failed"},
+		{"generic error", "eqgzi", []string{"an error occurred"}, true, ""},
+		{"warnings only", "awater", []string{"Warning: region 2 is untagged"}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := Failure(scanLog(t, tt.step, tt.lines))
+			if !tt.isFailed {
+				if failure != nil {
+					t.Errorf("failure = %s, want none", failure)
+				}
+				return
+			}
+			if failure == nil {
+				t.Fatal("no failure found")
+			}
+			if failure.Rule.Explanation != tt.want {
+				t.Errorf("failure = %s, want explanation %q", failure, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package build
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType is the kind of event a pipeline emits
+type EventType int
+
+// Event types, in the order a step emits them
+const (
+	EventStepStarted EventType = iota
+	EventProgress
+	EventArtifact
+	EventStepFinished
+)
+
+// Event reports pipeline progress to the UI, CLI and logs
+type Event struct {
+	Type EventType
+	Zone string
+	Step string
+	// Index is the position of Step in the run, Count is the number of steps in the run
+	Index int
+	Count int
+	// Progress is how far Step is from 0 to 1, set on EventProgress
+	Progress float64
+	// Elapsed is how long Step took, set on EventStepFinished
+	Elapsed time.Duration
+	// Err is set on EventStepFinished if Step failed
+	Err error
+	// Reused is set on EventStepFinished if Step was skipped since its inputs are unchanged
+	Reused bool
+	// Path and Size describe the file of an EventArtifact
+	Path string
+	Size int64
+}
+
+// Overall returns the progress of the whole run from 0 to 1
+func (e Event) Overall() float64 {
+	if e.Count == 0 {
+		return 0
+	}
+	step := 0.0
+	switch e.Type {
+	case EventProgress:
+		step = e.Progress
+	case EventArtifact, EventStepFinished:
+		step = 1
+	}
+	value := (float64(e.Index) + step) / float64(e.Count)
+	if value > 1 {
+		value = 1
+	}
+	return value
+}
+
+// String returns a one line description of the event
+func (e Event) String() string {
+	prefix := fmt.Sprintf("[%d/%d] %s", e.Index+1, e.Count, e.Step)
+	switch e.Type {
+	case EventStepStarted:
+		return prefix + " started"
+	case EventProgress:
+		return fmt.Sprintf("%s %d%%", prefix, int(e.Progress*100))
+	case EventArtifact:
+		return fmt.Sprintf("%s produced %s (%d bytes)", prefix, e.Path, e.Size)
+	case EventStepFinished:
+		if e.Reused {
+			return prefix + " reused, inputs unchanged"
+		}
+		if e.Err != nil {
+			return fmt.Sprintf("%s failed after %s: %s", prefix, e.Elapsed.Round(time.Millisecond), e.Err)
+		}
+		return fmt.Sprintf("%s finished in %s", prefix, e.Elapsed.Round(time.Millisecond))
+	}
+	return prefix
+}
+
+func (b *Builder) emit(e Event) {
+	if b.OnEvent == nil {
+		return
+	}
+	e.Zone = b.Zone
+	e.Index = b.stepIndex
+	e.Count = b.stepCount
+	b.OnEvent(e)
+}
@@ -0,0 +1,91 @@
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// moveFile renames src to dst, falling back to a copy when they are on different volumes
+func moveFile(src string, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	err = copyFile(src, dst)
+	if err != nil {
+		return fmt.Errorf("move %s: %w", filepath.Base(src), err)
+	}
+	return os.Remove(src)
+}
+
+func copyFile(src string, dst string) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// copyGlob copies every file matching pattern into the dst folder
+func copyGlob(pattern string, dst string) error {
+	if dst == "" {
+		return fmt.Errorf("destination path is not set")
+	}
+	fi, err := os.Stat(dst)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("destination %s is not a directory", dst)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("glob %s: %w", pattern, err)
+	}
+	count := 0
+	for _, src := range matches {
+		fi, err := os.Stat(src)
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", filepath.Base(src), err)
+		}
+		if fi.IsDir() {
+			continue
+		}
+		err = copyFile(src, filepath.Join(dst, filepath.Base(src)))
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", filepath.Base(src), err)
+		}
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("no files match %s", filepath.Base(pattern))
+	}
+	return nil
+}
+
+func normalizeNewlines(data []byte) string {
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+}
@@ -0,0 +1,366 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xackery/eqgzi-manager/tool"
+)
+
+// DefaultHistoryLimit is how many runs a zone keeps when no limit is set
+const DefaultHistoryLimit = 50
+
+// runTimeFormat names a run folder after the time it started
+const runTimeFormat = "20060102-150405.000"
+
+// blenderVersionPattern matches the version Blender prints when it starts
+var blenderVersionPattern = regexp.MustCompile(`^Blender (\d+\.\d+(?:\.\d+)?)`)
+
+// artifactPatterns are the build outputs a run records, relative to the zone folder
+var artifactPatterns = []string{"out/*.eqg", "map/*.map", "map/*.wtr"}
+
+// Run is the record of one build of a zone, kept in the zone's .build/history folder
+type Run struct {
+	ID             string        `json:"id"`
+	Zone           string        `json:"zone"`
+	Time           time.Time     `json:"time"`
+	Duration       time.Duration `json:"duration"`
+	Result         string        `json:"result"`
+	Error          string        `json:"error,omitempty"`
+	ManagerVersion string        `json:"manager_version"`
+	EQGZIVersion   string        `json:"eqgzi_version"`
+	BlenderVersion string        `json:"blender_version"`
+	Steps          []RunStep     `json:"steps"`
+	Diagnostics    []string      `json:"diagnostics,omitempty"`
+	Artifacts      []Artifact    `json:"artifacts"`
+	// Logs are the log files copied into the run folder
+	Logs []string `json:"logs"`
+	dir  string
+}
+
+// RunStep is how a step of a run went
+type RunStep struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Reused   bool          `json:"reused,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Artifact is a build output and its size and sha256
+type Artifact struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+}
+
+// ArtifactChange compares an output between two runs, a size of -1 means it is missing from that run
+type ArtifactChange struct {
+	Path      string
+	OldSize   int64
+	NewSize   int64
+	IsChanged bool
+}
+
+// Dir returns the folder holding the run record and its logs
+func (r *Run) Dir() string {
+	return r.dir
+}
+
+// Artifact returns the output at path, or nil
+func (r *Run) Artifact(path string) *Artifact {
+	for i := range r.Artifacts {
+		if r.Artifacts[i].Path == path {
+			return &r.Artifacts[i]
+		}
+	}
+	return nil
+}
+
+// Size returns the combined size of the outputs of the run
+func (r *Run) Size() int64 {
+	total := int64(0)
+	for _, a := range r.Artifacts {
+		total += a.Size
+	}
+	return total
+}
+
+// DiffArtifacts compares the outputs of an older and a newer run
+func DiffArtifacts(older *Run, newer *Run) []ArtifactChange {
+	paths := map[string]bool{}
+	for _, a := range older.Artifacts {
+		paths[a.Path] = true
+	}
+	for _, a := range newer.Artifacts {
+		paths[a.Path] = true
+	}
+	changes := []ArtifactChange{}
+	for path := range paths {
+		change := ArtifactChange{Path: path, OldSize: -1, NewSize: -1}
+		oldArtifact := older.Artifact(path)
+		newArtifact := newer.Artifact(path)
+		if oldArtifact != nil {
+			change.OldSize = oldArtifact.Size
+		}
+		if newArtifact != nil {
+			change.NewSize = newArtifact.Size
+		}
+		change.IsChanged = oldArtifact == nil || newArtifact == nil || oldArtifact.Hash != newArtifact.Hash
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// IsBuilt returns true if the run got to build its outputs, rather than being cancelled
+// or failing the checks before its first step
+func (r *Run) IsBuilt() bool {
+	return r.Result == "ok" || r.Result == "failed"
+}
+
+// PreviousRun returns the newest built run older than runs[i], or nil. runs are newest first, as Runs returns them
+func PreviousRun(runs []*Run, i int) *Run {
+	for _, run := range runs[i+1:] {
+		if run.IsBuilt() {
+			return run
+		}
+	}
+	return nil
+}
+
+// Runs returns the recorded builds of zone, newest first
+func Runs(currentPath string, zone string) ([]*Run, error) {
+	dir := filepath.Join(currentPath, "zones", zone, ".build", "history")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	runs := []*Run{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), "run.json"))
+		if err != nil {
+			continue
+		}
+		run := &Run{}
+		err = json.Unmarshal(data, run)
+		if err != nil {
+			continue
+		}
+		run.dir = filepath.Join(dir, entry.Name())
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Time.After(runs[j].Time) })
+	return runs, nil
+}
+
+// FindRun returns the run of zone with id, or the newest run whose id starts with it
+func FindRun(currentPath string, zone string, id string) (*Run, error) {
+	runs, err := Runs(currentPath, zone)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if run.ID == id || strings.HasPrefix(run.ID, id) {
+			return run, nil
+		}
+	}
+	return nil, fmt.Errorf("run %s of %s not found", id, zone)
+}
+
+// saveRun records the build that started at start and ended with buildErr, then prunes old runs
+func (b *Builder) saveRun(start time.Time, buildErr error) error {
+	run := &Run{
+		ID:             start.Format(runTimeFormat),
+		Zone:           b.Zone,
+		Time:           start,
+		Duration:       time.Since(start),
+		Result:         "ok",
+		ManagerVersion: strings.TrimSpace(b.Version),
+		EQGZIVersion:   b.EQGZIVersion,
+		BlenderVersion: b.blenderVersion,
+		Steps:          b.runSteps,
+	}
+	if run.EQGZIVersion == "" {
+		run.EQGZIVersion = b.newestEQGZIVersion()
+	}
+	if run.BlenderVersion == "" && b.isReused("blender") {
+		// a reused export ran with the Blender of the run before
+		runs, err := Runs(b.CurrentPath, b.Zone)
+		if err == nil && len(runs) > 0 {
+			run.BlenderVersion = runs[0].BlenderVersion
+		}
+	}
+	if buildErr != nil {
+		run.Result = "failed"
+		if len(b.runSteps) == 0 {
+			run.Result = "not started"
+		}
+		if errors.Is(buildErr, context.Canceled) {
+			run.Result = "cancelled"
+		}
+		run.Error = buildErr.Error()
+	}
+	for _, d := range b.Diagnostics {
+		run.Diagnostics = append(run.Diagnostics, fmt.Sprintf("%s: %s", d.Rule.Severity, d))
+	}
+	for _, pattern := range artifactPatterns {
+		matches, err := filepath.Glob(b.zonePath(pattern))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			state, err := hashFile(match)
+			if err != nil {
+				continue
+			}
+			rel, err := filepath.Rel(b.zonePath(""), match)
+			if err != nil {
+				continue
+			}
+			run.Artifacts = append(run.Artifacts, Artifact{Path: filepath.ToSlash(rel), Size: state.Size, Hash: state.Hash})
+		}
+	}
+
+	dir := b.buildPath("history/%s", run.ID)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("mkdir run: %w", err)
+	}
+	for _, logName := range b.runLogs {
+		err = copyFile(b.zonePath(logName), filepath.Join(dir, logName))
+		if err != nil {
+			continue
+		}
+		run.Logs = append(run.Logs, logName)
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode run: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "run.json"), data, 0644)
+	if err != nil {
+		return fmt.Errorf("write run: %w", err)
+	}
+	return b.pruneRuns()
+}
+
+// isReused returns true if the step named name was reused by the last run
+func (b *Builder) isReused(name string) bool {
+	for _, reused := range b.Reused {
+		if reused == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newestEQGZIVersion returns the eqgzi version a zone without a pinned version builds with
+func (b *Builder) newestEQGZIVersion() string {
+	versions, err := tool.New(b.CurrentPath).Versions(tool.EQGZI)
+	if err != nil || len(versions) == 0 {
+		return "unversioned"
+	}
+	return versions[0]
+}
+
+// pruneRuns removes the oldest runs past the history limit
+func (b *Builder) pruneRuns() error {
+	limit := b.HistoryLimit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	runs, err := Runs(b.CurrentPath, b.Zone)
+	if err != nil {
+		return err
+	}
+	for i := limit; i < len(runs); i++ {
+		err = os.RemoveAll(runs[i].dir)
+		if err != nil {
+			return fmt.Errorf("remove run %s: %w", runs[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// Details returns a multi-line description of the run, comparing its outputs to previous if set
+func (r *Run) Details(previous *Run) string {
+	lines := []string{
+		fmt.Sprintf("Run %s: %s in %s", r.ID, r.Result, r.Duration.Round(100*time.Millisecond)),
+		fmt.Sprintf("eqgzi-manager %s, eqgzi %s, Blender %s", r.ManagerVersion, r.EQGZIVersion, valueOr(r.BlenderVersion, "unknown")),
+	}
+	if r.Error != "" {
+		lines = append(lines, fmt.Sprintf("Error: %s", r.Error))
+	}
+	lines = append(lines, "", "Steps:")
+	for _, step := range r.Steps {
+		text := fmt.Sprintf("  %s %s", step.Name, step.Duration.Round(time.Millisecond))
+		if step.Reused {
+			text = fmt.Sprintf("  %s reused", step.Name)
+		}
+		if step.Error != "" {
+			text += fmt.Sprintf(", failed: %s", step.Error)
+		}
+		lines = append(lines, text)
+	}
+	if len(r.Diagnostics) > 0 {
+		lines = append(lines, "", "Problems:")
+		for _, d := range r.Diagnostics {
+			lines = append(lines, "  "+d)
+		}
+	}
+	lines = append(lines, "", "Outputs:")
+	if previous == nil {
+		for _, a := range r.Artifacts {
+			lines = append(lines, fmt.Sprintf("  %s %d bytes", a.Path, a.Size))
+		}
+		return strings.Join(lines, "\n")
+	}
+	lines[len(lines)-1] = fmt.Sprintf("Outputs compared to %s:", previous.ID)
+	for _, change := range DiffArtifacts(previous, r) {
+		lines = append(lines, "  "+change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String describes the change as path old -> new bytes
+func (c ArtifactChange) String() string {
+	switch {
+	case c.OldSize < 0:
+		return fmt.Sprintf("%s added, %d bytes", c.Path, c.NewSize)
+	case c.NewSize < 0:
+		return fmt.Sprintf("%s removed, was %d bytes", c.Path, c.OldSize)
+	case !c.IsChanged:
+		return fmt.Sprintf("%s unchanged, %d bytes", c.Path, c.NewSize)
+	}
+	return fmt.Sprintf("%s %d -> %d bytes (%s)", c.Path, c.OldSize, c.NewSize, SizeDelta(c.OldSize, c.NewSize))
+}
+
+// SizeDelta returns the change from old to new bytes as a signed count and percentage
+func SizeDelta(old int64, new int64) string {
+	delta := new - old
+	if old == 0 {
+		return fmt.Sprintf("%+d", delta)
+	}
+	return fmt.Sprintf("%+d, %+.1f%%", delta, float64(delta)*100/float64(old))
+}
+
+func valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
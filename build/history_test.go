@@ -0,0 +1,77 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPreviousRun(t *testing.T) {
+	runs := []*Run{
+		{ID: "5", Result: "ok"},
+		{ID: "4", Result: "cancelled"},
+		{ID: "3", Result: "not started"},
+		{ID: "2", Result: "failed"},
+		{ID: "1", Result: "ok"},
+		{ID: "0", Result: "cancelled"},
+	}
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "2"},
+		{1, "2"},
+		{2, "2"},
+		{3, "1"},
+		{4, ""},
+		{5, ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		previous := PreviousRun(runs, tt.index)
+		if previous != nil {
+			got = previous.ID
+		}
+		if got != tt.want {
+			t.Errorf("PreviousRun(%s) = %q, want %q", runs[tt.index].ID, got, tt.want)
+		}
+	}
+}
+
+func TestSaveRunResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		steps []RunStep
+		want  string
+	}{
+		{"ok", nil, []RunStep{{Name: "blender"}}, "ok"},
+		{"failed", errors.New("eqgzi failed"), []RunStep{{Name: "blender"}, {Name: "eqgzi", Error: "eqgzi failed"}}, "failed"},
+		{"not started", errors.New("blender 4.0.2 is not supported"), nil, "not started"},
+		{"cancelled", fmt.Errorf("blender: %w", context.Canceled), []RunStep{{Name: "blender"}}, "cancelled"},
+		{"cancelled before a step", context.Canceled, nil, "cancelled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentPath := t.TempDir()
+			b := &Builder{CurrentPath: currentPath, Zone: "testzone", EQGZIVersion: "1.0.0"}
+			b.runSteps = tt.steps
+			err := b.saveRun(time.Now(), tt.err)
+			if err != nil {
+				t.Fatalf("save run: %v", err)
+			}
+			runs, err := Runs(currentPath, "testzone")
+			if err != nil {
+				t.Fatalf("runs: %v", err)
+			}
+			if len(runs) != 1 {
+				t.Fatalf("runs = %d, want 1", len(runs))
+			}
+			if runs[0].Result != tt.want {
+				t.Errorf("result = %s, want %s", runs[0].Result, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package build
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/launch"
+	"github.com/xackery/eqgzi-manager/tool"
+)
+
+// lanternFormat is the LanternExtractor ModelExportFormat for glTF, which Blender imports with materials
+const lanternFormat = "2"
+
+// textureExts are extracted files copied beside the zone .blend
+var textureExts = map[string]bool{".png": true, ".dds": true, ".bmp": true, ".jpg": true, ".tga": true}
+
+// EQZones returns the zone short names with an .s3d in eqPath
+func EQZones(eqPath string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(eqPath, "*.s3d"))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+	zones := []string{}
+	for _, match := range matches {
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(match), filepath.Ext(match)))
+		// _obj, _chr and _lit archives belong to a zone, the rest are shared models
+		if strings.Contains(name, "_") || strings.HasPrefix(name, "global") || strings.HasPrefix(name, "gequip") || strings.HasPrefix(name, "sky") {
+			continue
+		}
+		zones = append(zones, name)
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// ImportPipeline extracts eqZone from EQPath with LanternExtractor into the zone folder.
+// If isBlend is set, the extracted model is also imported into the zone .blend
+func (b *Builder) ImportPipeline(eqZone string, isBlend bool) *Pipeline {
+	lanternPath := b.lanternPath()
+	exportPath := filepath.Join(lanternPath, "Exports", eqZone)
+	p := &Pipeline{
+		Steps: []*Step{
+			{
+				Name: "settings",
+				Action: func(b *Builder) error {
+					_, err := os.Stat(filepath.Join(b.EQPath, eqZone+".s3d"))
+					if err != nil {
+						return fmt.Errorf("%s.s3d not found in EverQuest path: %w", eqZone, err)
+					}
+					return b.writeLanternSettings(filepath.Join(lanternPath, "settings.txt"))
+				},
+			},
+			{
+				Name:    "lantern",
+				Command: launch.Executable(lanternPath, "LanternExtractor"),
+				Args:    []string{eqZone},
+				Dir:     lanternPath,
+			},
+			{
+				Name:    "scaffold",
+				Outputs: []string{"import"},
+				Action: func(b *Builder) error {
+					return b.scaffoldImport(exportPath)
+				},
+			},
+		},
+	}
+	if isBlend {
+		p.Steps = append(p.Steps, &Step{
+			Name:      "blendimport",
+			Inputs:    []string{"import"},
+			Outputs:   []string{fmt.Sprintf("%s.blend", b.Zone)},
+			Command:   b.BlenderExecutable(),
+			Args:      []string{"--background", b.Zone + ".blend", "--python-expr", blendImportExpr},
+			IsBlender: true,
+		})
+	}
+	return p
+}
+
+// Import runs the import pipeline for the zone, which must already exist
+func (b *Builder) Import(ctx context.Context, eqZone string, isBlend bool) error {
+	b.Diagnostics = nil
+	_, err := tool.New(b.CurrentPath).Dir(tool.Lantern, b.LanternVersion)
+	if err != nil {
+		return err
+	}
+	b.logf("Importing %s from EverQuest as %s", eqZone, b.Zone)
+	return b.RunPipeline(ctx, b.ImportPipeline(eqZone, isBlend), "import.log")
+}
+
+// lanternPath returns the folder of the LanternExtractor version in use
+func (b *Builder) lanternPath() string {
+	dir, err := tool.New(b.CurrentPath).Dir(tool.Lantern, b.LanternVersion)
+	if err != nil {
+		return filepath.Join(b.CurrentPath, "tools")
+	}
+	return dir
+}
+
+// writeLanternSettings points LanternExtractor at EQPath and glTF export, keeping its other settings
+func (b *Builder) writeLanternSettings(path string) error {
+	values := map[string]string{
+		"EverQuestDirectory": strings.TrimSuffix(filepath.ToSlash(b.EQPath), "/") + "/",
+		"ModelExportFormat":  lanternFormat,
+	}
+	lines := []string{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read settings.txt: %w", err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, _, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		value, isSet := values[key]
+		if ok && isSet {
+			line = fmt.Sprintf("%s = %s", key, value)
+			delete(values, key)
+		}
+		lines = append(lines, line)
+	}
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s = %s", key, values[key]))
+	}
+	err = os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	if err != nil {
+		return fmt.Errorf("write settings.txt: %w", err)
+	}
+	return nil
+}
+
+// scaffoldImport copies a LanternExtractor export into the zone import folder, and its textures beside the .blend
+func (b *Builder) scaffoldImport(exportPath string) error {
+	_, err := os.Stat(exportPath)
+	if err != nil {
+		return fmt.Errorf("lantern export: %w", err)
+	}
+	importPath := b.zonePath("import")
+	err = os.RemoveAll(importPath)
+	if err != nil {
+		return fmt.Errorf("remove import: %w", err)
+	}
+
+	modelCount := 0
+	textureCount := 0
+	err = filepath.WalkDir(exportPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(exportPath, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(importPath, rel)
+		if d.IsDir() {
+			return os.MkdirAll(dst, os.ModePerm)
+		}
+		err = copyFile(path, dst)
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", rel, err)
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == ".gltf" || ext == ".glb" || ext == ".obj" {
+			modelCount++
+		}
+		if !textureExts[ext] {
+			return nil
+		}
+		err = copyFile(path, b.zonePath(strings.ToLower(d.Name())))
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", d.Name(), err)
+		}
+		textureCount++
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scaffold: %w", err)
+	}
+
+	if modelCount == 0 {
+		return fmt.Errorf("lantern exported no .gltf, .glb or .obj model")
+	}
+	b.logf("Imported %d models and %d textures", modelCount, textureCount)
+	return nil
+}
+
+// blendImportExpr is run by blender to import the extracted model into the zone .blend.
+// The zone mesh is the shortest path, objects are exported into subfolders
+const blendImportExpr = `import bpy, glob, os
+found = []
+for ext in ('gltf', 'glb', 'obj'):
+    found += glob.glob(os.path.join('import', '**', '*.' + ext), recursive=True)
+path = sorted(found, key=len)[0]
+if path.lower().endswith('.obj'):
+    bpy.ops.import_scene.obj(filepath=path)
+else:
+    bpy.ops.import_scene.gltf(filepath=path)
+bpy.ops.wm.save_mainfile()
+print('Imported', path)
+`
@@ -0,0 +1,84 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEQZones(t *testing.T) {
+	eqPath := t.TempDir()
+	for _, name := range []string{"gfaydark.s3d", "gfaydark_obj.s3d", "gfaydark_chr.s3d", "gfaydark_lit.s3d", "Qeynos.s3d", "crushbone.s3d", "global_chr.s3d", "global4_chr.s3d", "gequip.s3d", "gequip2.s3d", "sky.s3d", "skyfire.s3d", "crushbone.eqg", "spells.eff"} {
+		err := os.WriteFile(filepath.Join(eqPath, name), nil, 0644)
+		if err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	zones, err := EQZones(eqPath)
+	if err != nil {
+		t.Fatalf("eq zones: %v", err)
+	}
+	want := []string{"crushbone", "gfaydark", "qeynos"}
+	if !reflect.DeepEqual(zones, want) {
+		t.Errorf("EQZones = %v, want %v", zones, want)
+	}
+
+	zones, err = EQZones(filepath.Join(eqPath, "missing"))
+	if err != nil || len(zones) != 0 {
+		t.Errorf("EQZones of a missing folder = %v, %v, want none", zones, err)
+	}
+}
+
+func TestWriteLanternSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		eqPath   string
+		settings string
+		want     string
+	}{
+		{"missing", "/games/eq", "", "EverQuestDirectory = /games/eq/\nModelExportFormat = 2\n"},
+		{"trailing slash", "/games/eq/", "", "EverQuestDirectory = /games/eq/\nModelExportFormat = 2\n"},
+		{
+			"replaces",
+			"/games/eq",
+			"// LanternExtractor settings\nEverQuestDirectory = C:/old/\nRawS3DExtract = false\nModelExportFormat = 0\n",
+			"// LanternExtractor settings\nEverQuestDirectory = /games/eq/\nRawS3DExtract = false\nModelExportFormat = 2\n",
+		},
+		{
+			"unspaced",
+			"/games/eq",
+			"EverQuestDirectory=C:/old/\nExportZoneWithObjects=true",
+			"EverQuestDirectory = /games/eq/\nExportZoneWithObjects=true\nModelExportFormat = 2\n",
+		},
+		{
+			"comment mentions key",
+			"/games/eq",
+			"// set ModelExportFormat below\nLogLevel = 1\n",
+			"// set ModelExportFormat below\nLogLevel = 1\nEverQuestDirectory = /games/eq/\nModelExportFormat = 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.txt")
+			if tt.settings != "" {
+				err := os.WriteFile(path, []byte(tt.settings), 0644)
+				if err != nil {
+					t.Fatalf("write settings: %v", err)
+				}
+			}
+			b := &Builder{EQPath: tt.eqPath}
+			err := b.writeLanternSettings(path)
+			if err != nil {
+				t.Fatalf("write lantern settings: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read settings: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("settings.txt = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+// ZonePipeline returns the convert pipeline of the zone with its manifest applied
+func (b *Builder) ZonePipeline() (*Pipeline, error) {
+	if b.manifestErr != nil {
+		return nil, b.manifestErr
+	}
+	p := b.ConvertPipeline()
+	err := b.applyManifest(p)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// applyManifest adds the extra outputs and steps of the zone manifest to the convert pipeline p,
+// then skips and reorders its steps
+func (b *Builder) applyManifest(p *Pipeline) error {
+	m := b.Manifest
+	if m == nil {
+		return nil
+	}
+	if p.Step("blender") == nil {
+		if len(m.Skip) > 0 || len(m.Order) > 0 || len(m.Steps) > 0 || len(m.ExtraOutputs) > 0 {
+			b.logf("Ignoring %s build steps, a customized convert.bat runs instead", zone.ManifestName)
+		}
+		return nil
+	}
+
+	if len(m.ExtraOutputs) > 0 {
+		p.Steps = append(p.Steps, b.extraOutputStep())
+	}
+	for _, ms := range m.Steps {
+		err := p.Insert(ms.Before, b.manifestStep(ms))
+		if err != nil {
+			return fmt.Errorf("%s step %s: %w", zone.ManifestName, ms.Name, err)
+		}
+	}
+	for _, name := range m.Skip {
+		if p.Step(name) == nil {
+			return fmt.Errorf("%s skips step %s, which doesn't exist", zone.ManifestName, name)
+		}
+	}
+	p.Skip(m.Skip...)
+	if len(m.Order) > 0 {
+		err := p.Reorder(m.Order)
+		if err != nil {
+			return fmt.Errorf("%s order: %w", zone.ManifestName, err)
+		}
+	}
+	return nil
+}
+
+// manifestStep turns an extra step of the zone manifest into a pipeline step
+func (b *Builder) manifestStep(ms zone.ManifestStep) *Step {
+	command := strings.ReplaceAll(ms.Command, "{zone}", b.Zone)
+	if strings.ContainsAny(command, `/\`) && !filepath.IsAbs(command) {
+		command = b.zonePath(command)
+	}
+	args := []string{}
+	for _, arg := range ms.Args {
+		args = append(args, strings.ReplaceAll(arg, "{zone}", b.Zone))
+	}
+	return &Step{
+		Name:    ms.Name,
+		Inputs:  ms.Inputs,
+		Outputs: ms.Outputs,
+		Command: command,
+		Args:    args,
+		Dir:     ms.Dir,
+	}
+}
+
+// extraOutputStep copies the extra outputs of the zone manifest to out/
+func (b *Builder) extraOutputStep() *Step {
+	outputs := []string{}
+	for _, path := range b.Manifest.ExtraOutputs {
+		outputs = append(outputs, "out/"+filepath.Base(path))
+	}
+	return &Step{
+		Name:    "extraoutputs",
+		Inputs:  b.Manifest.ExtraOutputs,
+		Outputs: outputs,
+		Action: func(b *Builder) error {
+			for _, path := range b.Manifest.ExtraOutputs {
+				err := copyFile(b.zonePath(path), b.zonePath("out/%s", filepath.Base(path)))
+				if err != nil {
+					return fmt.Errorf("extra output %s: %w", path, err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// copySteps returns a step for each copy of the zone manifest whose target starts with kind,
+// or is an absolute folder when kind is empty
+func (b *Builder) copySteps(kind string) []*Step {
+	if b.Manifest == nil {
+		return nil
+	}
+	steps := []*Step{}
+	for i, c := range b.Manifest.Copies {
+		target := filepath.ToSlash(c.Target)
+		dst := ""
+		switch {
+		case kind == "" && filepath.IsAbs(c.Target):
+			dst = c.Target
+		case kind == "eq" && (target == "eq" || strings.HasPrefix(target, "eq/")):
+			dst = filepath.Join(b.EQPath, strings.TrimPrefix(target, "eq"))
+		case kind == "server" && (target == "server" || strings.HasPrefix(target, "server/")):
+			dst = filepath.Join(b.ServerPath, strings.TrimPrefix(target, "server"))
+		default:
+			continue
+		}
+		files := c.Files
+		steps = append(steps, &Step{
+			Name:   fmt.Sprintf("copy%d", i+1),
+			Inputs: []string{files},
+			Action: func(b *Builder) error {
+				if kind == "eq" && b.EQPath == "" {
+					return fmt.Errorf("eq_path is not set")
+				}
+				if kind == "server" && b.ServerPath == "" {
+					return fmt.Errorf("server_path is not set")
+				}
+				return copyGlob(b.zonePath(files), dst)
+			},
+		})
+	}
+	return steps
+}
+
+// CopyExtraPipeline returns the steps that copy files to the absolute folders of the zone manifest
+func (b *Builder) CopyExtraPipeline() *Pipeline {
+	return &Pipeline{Steps: b.copySteps("")}
+}
+
+// checkManifest returns an error if the zone manifest couldn't be read
+func (b *Builder) checkManifest() error {
+	if b.manifestErr != nil {
+		return b.manifestErr
+	}
+	if b.Manifest != nil && b.Manifest.LongName != "" {
+		b.logf("Building %s (%s)", b.Manifest.LongName, b.Zone)
+	}
+	return nil
+}
+
+// loadManifest reads the zone manifest, applying its blender and eqgzi versions unless cfg pins the zone
+func (b *Builder) loadManifest(zonePin string) {
+	m, err := zone.LoadManifest(b.zonePath(""))
+	if err != nil {
+		b.manifestErr = err
+		return
+	}
+	if _, err := os.Stat(b.zonePath(zone.ManifestName)); err != nil {
+		return
+	}
+	b.Manifest = m
+	if m.BlenderPath != "" {
+		b.BlenderPath = m.BlenderPath
+	}
+	if m.EQGZIVersion != "" && zonePin == "" {
+		b.EQGZIVersion = m.EQGZIVersion
+	}
+}
+
+func (b *Builder) longName() string {
+	if b.Manifest == nil {
+		return ""
+	}
+	return b.Manifest.LongName
+}
+
+func (b *Builder) zoneID() int {
+	if b.Manifest == nil {
+		return 0
+	}
+	return b.Manifest.ZoneID
+}
@@ -0,0 +1,231 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+// stepNames returns the names of the steps of p, in order
+func stepNames(p *Pipeline) []string {
+	names := []string{}
+	for _, step := range p.Steps {
+		names = append(names, step.Name)
+	}
+	return names
+}
+
+func TestApplyManifest(t *testing.T) {
+	navmesh := zone.ManifestStep{Name: "navmesh", Command: "tools/navmesh.exe", Args: []string{"{zone}"}}
+	tests := []struct {
+		name     string
+		manifest *zone.Manifest
+		want     []string
+		wantErr  string
+	}{
+		{"none", nil, []string{"blender", "eqgzi", "azone", "awater"}, ""},
+		{"empty", &zone.Manifest{}, []string{"blender", "eqgzi", "azone", "awater"}, ""},
+		{"skip", &zone.Manifest{Skip: []string{"awater"}}, []string{"blender", "eqgzi", "azone"}, ""},
+		{"skip missing", &zone.Manifest{Skip: []string{"water"}}, nil, "skips step water, which doesn't exist"},
+		{"reorder", &zone.Manifest{Order: []string{"eqgzi", "blender"}}, []string{"eqgzi", "blender", "azone", "awater"}, ""},
+		{"reorder missing", &zone.Manifest{Order: []string{"blender", "water"}}, nil, "order: step water not found"},
+		{"insert last", &zone.Manifest{Steps: []zone.ManifestStep{navmesh}}, []string{"blender", "eqgzi", "azone", "awater", "navmesh"}, ""},
+		{"insert before azone", &zone.Manifest{Steps: []zone.ManifestStep{withBefore(navmesh, "azone")}}, []string{"blender", "eqgzi", "navmesh", "azone", "awater"}, ""},
+		{"insert before missing", &zone.Manifest{Steps: []zone.ManifestStep{withBefore(navmesh, "water")}}, nil, "step navmesh: step water not found"},
+		{"insert existing", &zone.Manifest{Steps: []zone.ManifestStep{{Name: "azone", Command: "azone"}}}, nil, "step azone: step azone already exists"},
+		{"insert before inserted", &zone.Manifest{Steps: []zone.ManifestStep{withBefore(navmesh, "azone"), {Name: "lint", Command: "lint", Before: "navmesh"}}}, []string{"blender", "eqgzi", "lint", "navmesh", "azone", "awater"}, ""},
+		{"extra outputs", &zone.Manifest{ExtraOutputs: []string{"extra/sky.txt"}}, []string{"blender", "eqgzi", "azone", "awater", "extraoutputs"}, ""},
+		{"extra outputs before", &zone.Manifest{ExtraOutputs: []string{"sky.txt"}, Steps: []zone.ManifestStep{withBefore(navmesh, "extraoutputs")}}, []string{"blender", "eqgzi", "azone", "awater", "navmesh", "extraoutputs"}, ""},
+		{
+			"skip and reorder inserted",
+			&zone.Manifest{Skip: []string{"awater"}, Order: []string{"navmesh", "blender"}, Steps: []zone.ManifestStep{navmesh}},
+			[]string{"navmesh", "blender", "eqgzi", "azone"},
+			"",
+		},
+		{"skip inserted", &zone.Manifest{Skip: []string{"navmesh"}, Steps: []zone.ManifestStep{navmesh}}, []string{"blender", "eqgzi", "azone", "awater"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Builder{CurrentPath: t.TempDir(), Zone: "testzone", Manifest: tt.manifest}
+			p := b.ConvertPipeline()
+			err := b.applyManifest(p)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyManifest error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyManifest: %v", err)
+			}
+			if got := stepNames(p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("steps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// withBefore returns ms run before the step named before
+func withBefore(ms zone.ManifestStep, before string) zone.ManifestStep {
+	ms.Before = before
+	return ms
+}
+
+func TestApplyManifestSteps(t *testing.T) {
+	currentPath := t.TempDir()
+	b := &Builder{CurrentPath: currentPath, Zone: "testzone", Manifest: &zone.Manifest{
+		ExtraOutputs: []string{"extra/sky.txt", "lights.txt"},
+		Steps: []zone.ManifestStep{
+			{Name: "navmesh", Command: "tools/navmesh.exe", Args: []string{"{zone}", "out/{zone}.nav"}, Dir: "out", Inputs: []string{"out/testzone.eqg"}, Outputs: []string{"out/testzone.nav"}},
+			{Name: "lint", Command: "lint-{zone}", Before: "blender"},
+		},
+	}}
+	p := b.ConvertPipeline()
+	err := b.applyManifest(p)
+	if err != nil {
+		t.Fatalf("applyManifest: %v", err)
+	}
+
+	navmesh := p.Step("navmesh")
+	want := &Step{
+		Name:    "navmesh",
+		Command: filepath.Join(currentPath, "zones", "testzone", "tools", "navmesh.exe"),
+		Args:    []string{"testzone", "out/testzone.nav"},
+		Dir:     "out",
+		Inputs:  []string{"out/testzone.eqg"},
+		Outputs: []string{"out/testzone.nav"},
+	}
+	if !reflect.DeepEqual(navmesh, want) {
+		t.Errorf("navmesh = %+v, want %+v", navmesh, want)
+	}
+	if lint := p.Step("lint"); lint.Command != "lint-testzone" {
+		t.Errorf("lint command = %s, want lint-testzone from PATH", lint.Command)
+	}
+
+	extra := p.Step("extraoutputs")
+	if want := []string{"out/sky.txt", "out/lights.txt"}; !reflect.DeepEqual(extra.Outputs, want) {
+		t.Errorf("extraoutputs outputs = %v, want %v", extra.Outputs, want)
+	}
+	// eqgzi made out/ by the time extra outputs are copied
+	writeZoneFile(t, b, "out/testzone.eqg", "eqg")
+	for _, path := range b.Manifest.ExtraOutputs {
+		writeZoneFile(t, b, path, path)
+	}
+	err = extra.Action(b)
+	if err != nil {
+		t.Fatalf("extraoutputs: %v", err)
+	}
+	for _, path := range b.Manifest.ExtraOutputs {
+		data, err := os.ReadFile(b.zonePath("out/%s", filepath.Base(path)))
+		if err != nil || string(data) != path {
+			t.Errorf("out/%s = %q, %v, want a copy of %s", filepath.Base(path), data, err, path)
+		}
+	}
+}
+
+// A zone converted by its own script ignores the manifest's build steps
+func TestApplyManifestScript(t *testing.T) {
+	b := &Builder{CurrentPath: t.TempDir(), Zone: "testzone", Manifest: &zone.Manifest{Skip: []string{"awater"}, Order: []string{"missing"}}}
+	p := &Pipeline{Steps: []*Step{{Name: "convert.bat"}}}
+	err := b.applyManifest(p)
+	if err != nil {
+		t.Fatalf("applyManifest: %v", err)
+	}
+	if got, want := stepNames(p), []string{"convert.bat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("steps = %v, want %v", got, want)
+	}
+}
+
+// manifestCopies are copies to every kind of target, absPath is the absolute folder one
+func manifestCopies(absPath string) []zone.Copy {
+	return []zone.Copy{
+		{Files: "map/*.path", Target: "server/maps"},
+		{Files: "*.txt", Target: "eq"},
+		{Files: "ui/*.xml", Target: "eq/uifiles/default"},
+		{Files: "map/*.wtr", Target: "server"},
+		{Files: "notes.md", Target: absPath},
+		{Files: "eqx.txt", Target: "eqx"},
+		{Files: "serverless.txt", Target: "servers/x"},
+	}
+}
+
+func TestCopySteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline func(b *Builder) *Pipeline
+		want     []string
+		// copied are the files each copy step leaves in the workspace, relative to it
+		copied map[string][]string
+	}{
+		{
+			"eq",
+			(*Builder).CopyEQPipeline,
+			[]string{"copy", "copy2", "copy3"},
+			map[string][]string{"copy2": {"eq/sky.txt"}, "copy3": {"eq/uifiles/default/window.xml"}},
+		},
+		{
+			"server",
+			(*Builder).CopyServerPipeline,
+			[]string{"copymap", "copywater", "copy1", "copy4"},
+			map[string][]string{"copy1": {"server/maps/testzone.path"}, "copy4": {"server/testzone.wtr"}},
+		},
+		{
+			"extra",
+			(*Builder).CopyExtraPipeline,
+			[]string{"copy5"},
+			map[string][]string{"copy5": {"abs/notes.md"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentPath := t.TempDir()
+			b := &Builder{
+				CurrentPath: currentPath,
+				Zone:        "testzone",
+				EQPath:      filepath.Join(currentPath, "eq"),
+				ServerPath:  filepath.Join(currentPath, "server"),
+				Manifest:    &zone.Manifest{Copies: manifestCopies(filepath.Join(currentPath, "abs"))},
+			}
+			for _, dir := range []string{"eq/uifiles/default", "server/maps", "abs"} {
+				err := os.MkdirAll(filepath.Join(currentPath, dir), os.ModePerm)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, path := range []string{"map/testzone.path", "map/testzone.wtr", "sky.txt", "ui/window.xml", "notes.md", "eqx.txt", "serverless.txt"} {
+				writeZoneFile(t, b, path, path)
+			}
+
+			p := tt.pipeline(b)
+			if got := stepNames(p); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("steps = %v, want %v", got, tt.want)
+			}
+			for name, files := range tt.copied {
+				err := p.Step(name).Action(b)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				for _, file := range files {
+					_, err = os.Stat(filepath.Join(currentPath, filepath.FromSlash(file)))
+					if err != nil {
+						t.Errorf("%s didn't copy %s: %v", name, file, err)
+					}
+				}
+			}
+		})
+	}
+
+	b := &Builder{CurrentPath: t.TempDir(), Zone: "testzone", Manifest: &zone.Manifest{Copies: manifestCopies("")}}
+	err := b.CopyEQPipeline().Step("copy2").Action(b)
+	if err == nil || !strings.Contains(err.Error(), "eq_path is not set") {
+		t.Errorf("copy without eq_path = %v, want an error", err)
+	}
+	err = b.CopyServerPipeline().Step("copy1").Action(b)
+	if err == nil || !strings.Contains(err.Error(), "server_path is not set") {
+		t.Errorf("copy without server_path = %v, want an error", err)
+	}
+}
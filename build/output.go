@@ -0,0 +1,137 @@
+package build
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scriptStepPattern matches the "Step N" lines convert.py prints
+var scriptStepPattern = regexp.MustCompile(`^Step (\d+)`)
+
+// scriptStepCount is the number of steps convert.py prints when it succeeds
+const scriptStepCount = 7
+
+// outputLog is a zone log file and the state scanned from it so far
+type outputLog struct {
+	name string
+	w    *os.File
+	// lineNumber is the number of lines written to w
+	lineNumber int
+	// step is the name of the running pipeline step
+	step     string
+	isScript bool
+	// scriptStep is the last "Step N" reported by convert.py
+	scriptStep           int
+	isScriptStepExpected bool
+	nextLineRule         *Rule
+	diagnostics          []*Diagnostic
+}
+
+func (b *Builder) newOutputLog(logName string) (*outputLog, error) {
+	w, err := os.Create(b.zonePath(logName))
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %s", logName, err)
+	}
+	out := &outputLog{name: logName, w: w}
+	err = out.writeString(fmt.Sprintf("Initialized from eqgzi-manager v%s\n", strings.TrimSpace(b.Version)))
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("write to %s: %s", logName, err)
+	}
+	return out, nil
+}
+
+// writeString writes text to the log, counting lines
+func (o *outputLog) writeString(text string) error {
+	o.lineNumber += strings.Count(text, "\n")
+	_, err := o.w.WriteString(text)
+	return err
+}
+
+// Close closes the log file
+func (o *outputLog) Close() error {
+	return o.w.Close()
+}
+
+// Result returns the error that best explains a failure found in the log
+func (o *outputLog) Result() error {
+	failure := Failure(o.diagnostics)
+	if failure != nil {
+		return fmt.Errorf("%s", failure)
+	}
+	if o.isScriptStepExpected && o.scriptStep < scriptStepCount {
+		return fmt.Errorf("convert failed at step %d", o.scriptStep)
+	}
+	return nil
+}
+
+func (b *Builder) processOutput(in io.Reader, out *outputLog) error {
+	buf := bufio.NewReader(in)
+	logName := out.name
+
+	for {
+		line, err := buf.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("read %s: %s", logName, err)
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		lineNumber := out.lineNumber + 1
+		fmt.Printf("%s/%s:%d %s", b.Zone, logName, lineNumber, line)
+
+		matches := scriptStepPattern.FindStringSubmatch(line)
+		if len(matches) > 1 {
+			stepNum, err := strconv.Atoi(matches[1])
+			if err == nil {
+				out.scriptStep = stepNum
+				progress := float64(stepNum) / scriptStepCount
+				if progress > 1 {
+					progress = 1
+				}
+				b.emit(Event{Type: EventProgress, Step: out.step, Progress: progress})
+			}
+		}
+
+		if b.blenderVersion == "" && (out.step == "blender" || out.step == "convert.bat") {
+			matches = blenderVersionPattern.FindStringSubmatch(line)
+			if len(matches) > 1 {
+				b.blenderVersion = matches[1]
+			}
+		}
+
+		out.scan(line, lineNumber)
+
+		err = out.writeString(line)
+		if err != nil {
+			return fmt.Errorf("write string to %s: %s", logName, err)
+		}
+	}
+	return nil
+}
+
+// scan records a diagnostic if line matches a rule
+func (o *outputLog) scan(line string, lineNumber int) {
+	if o.nextLineRule != nil {
+		o.diagnostics = append(o.diagnostics, &Diagnostic{Rule: o.nextLineRule, Log: o.name, Line: lineNumber, Step: o.step, Text: line})
+		o.nextLineRule = nil
+		return
+	}
+	rule := o.match(line)
+	if rule == nil {
+		return
+	}
+	if rule.IsNextLine {
+		o.nextLineRule = rule
+		return
+	}
+	o.diagnostics = append(o.diagnostics, &Diagnostic{Rule: rule, Log: o.name, Line: lineNumber, Step: o.step, Text: line})
+}
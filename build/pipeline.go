@@ -0,0 +1,345 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/launch"
+	"github.com/xackery/eqgzi-manager/tool"
+)
+
+// Step is a single named stage of a zone build
+type Step struct {
+	Name string
+	// Inputs are files read by the step, relative to the zone folder unless absolute.
+	// Globs and folders are allowed, they fingerprint the step in cached pipelines
+	Inputs []string
+	// Outputs are files produced by the step, relative to the zone folder
+	Outputs []string
+	// Command is the executable to run, if any
+	Command string
+	Args    []string
+	// Env is the environment of Command, defaults to the builder environment
+	Env []string
+	// Dir is the working directory of Command, relative to the zone folder unless absolute
+	Dir string
+	// Action is native work done after Command exits successfully
+	Action func(b *Builder) error
+	// IsBlender marks a step that runs Blender, it waits for one of the builder's BlenderSlots
+	IsBlender bool
+}
+
+// Pipeline is an ordered set of steps
+type Pipeline struct {
+	Steps []*Step
+	// IsCached reuses the outputs of steps whose fingerprint is unchanged since they last succeeded
+	IsCached bool
+}
+
+// Step returns the step named name, or nil
+func (p *Pipeline) Step(name string) *Step {
+	for _, step := range p.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	return nil
+}
+
+// Skip removes the steps named names from the pipeline
+func (p *Pipeline) Skip(names ...string) {
+	steps := []*Step{}
+	for _, step := range p.Steps {
+		isSkipped := false
+		for _, name := range names {
+			if step.Name == name {
+				isSkipped = true
+				break
+			}
+		}
+		if isSkipped {
+			continue
+		}
+		steps = append(steps, step)
+	}
+	p.Steps = steps
+}
+
+// Insert adds step before the step named before, or at the end if before is empty
+func (p *Pipeline) Insert(before string, step *Step) error {
+	if p.Step(step.Name) != nil {
+		return fmt.Errorf("step %s already exists", step.Name)
+	}
+	if before == "" {
+		p.Steps = append(p.Steps, step)
+		return nil
+	}
+	for i, s := range p.Steps {
+		if s.Name != before {
+			continue
+		}
+		p.Steps = append(p.Steps[:i], append([]*Step{step}, p.Steps[i:]...)...)
+		return nil
+	}
+	return fmt.Errorf("step %s not found", before)
+}
+
+// Reorder sorts steps to match names. Steps not in names keep their relative order at the end
+func (p *Pipeline) Reorder(names []string) error {
+	steps := []*Step{}
+	for _, name := range names {
+		step := p.Step(name)
+		if step == nil {
+			return fmt.Errorf("step %s not found", name)
+		}
+		steps = append(steps, step)
+	}
+	for _, step := range p.Steps {
+		isListed := false
+		for _, name := range names {
+			if step.Name == name {
+				isListed = true
+				break
+			}
+		}
+		if !isListed {
+			steps = append(steps, step)
+		}
+	}
+	p.Steps = steps
+	return nil
+}
+
+// textureInputs are the textures and animation definitions eqgzi packs from a zone folder
+var textureInputs = []string{"*.png", "*.jpg", "*.jpeg", "*.dds", "*.bmp", "*.tga", "*.txt", "texture"}
+
+// blenderOutputs are the files convert.py exports for eqgzi to import. Newer eqgzi versions export glTF, older ones obj,
+// an output an eqgzi version doesn't write isn't recorded
+func blenderOutputs(zone string) []string {
+	return []string{
+		fmt.Sprintf("cache/%s.gltf", zone),
+		fmt.Sprintf("cache/%s.bin", zone),
+		fmt.Sprintf("cache/%s.obj", zone),
+		fmt.Sprintf("cache/%s.mtl", zone),
+	}
+}
+
+// ConvertPipeline returns the steps that turn <zone>.blend into out/<zone>.eqg and map/<zone>.map/.wtr
+func (b *Builder) ConvertPipeline() *Pipeline {
+	if b.useScript("convert.bat") {
+		return b.scriptPipeline("convert.bat")
+	}
+	zone := b.Zone
+	return &Pipeline{
+		IsCached: true,
+		Steps: []*Step{
+			{
+				Name:      "blender",
+				Inputs:    []string{zone + ".blend", filepath.Join(b.ToolsPath(), "convert.py")},
+				Outputs:   blenderOutputs(zone),
+				Command:   b.BlenderExecutable(),
+				Args:      []string{"--background", zone + ".blend", "--python", filepath.Join(b.ToolsPath(), "convert.py")},
+				IsBlender: true,
+			},
+			{
+				Name:    "eqgzi",
+				Inputs:  textureInputs,
+				Outputs: []string{fmt.Sprintf("out/%s.eqg", zone)},
+				Command: b.toolExecutable("eqgzi"),
+				Args:    []string{"import", zone},
+				Action: func(b *Builder) error {
+					return b.verifyArchive()
+				},
+			},
+			{
+				Name:    "azone",
+				Inputs:  []string{fmt.Sprintf("out/%s.eqg", zone)},
+				Outputs: []string{fmt.Sprintf("map/%s.map", zone)},
+				Command: b.toolExecutable("azone"),
+				Args:    []string{zone},
+				Dir:     "out",
+				Action: func(b *Builder) error {
+					err := removeIfExists(b.zonePath("out/azone.log"))
+					if err != nil {
+						return err
+					}
+					err = b.verifyMap()
+					if err != nil {
+						return err
+					}
+					err = os.RemoveAll(b.zonePath("map"))
+					if err != nil {
+						return fmt.Errorf("remove map: %w", err)
+					}
+					err = os.MkdirAll(b.zonePath("map"), os.ModePerm)
+					if err != nil {
+						return fmt.Errorf("mkdir map: %w", err)
+					}
+					return moveFile(b.zonePath("out/%s.map", b.Zone), b.zonePath("map/%s.map", b.Zone))
+				},
+			},
+			{
+				Name:    "awater",
+				Inputs:  []string{fmt.Sprintf("out/%s.eqg", zone)},
+				Outputs: []string{fmt.Sprintf("map/%s.wtr", zone)},
+				Command: b.toolExecutable("awater"),
+				Args:    []string{zone},
+				Dir:     "out",
+				Action: func(b *Builder) error {
+					err := removeIfExists(b.zonePath("out/awater.log"))
+					if err != nil {
+						return err
+					}
+					err = b.verifyWater()
+					if err != nil {
+						return err
+					}
+					return moveFile(b.zonePath("out/%s.wtr", b.Zone), b.zonePath("map/%s.wtr", b.Zone))
+				},
+			},
+		},
+	}
+}
+
+// CopyEQPipeline returns the steps that copy out/ and the zone manifest's eq copies to the EverQuest path
+func (b *Builder) CopyEQPipeline() *Pipeline {
+	if b.useScript("copy_eq.bat") {
+		p := b.scriptPipeline("copy_eq.bat")
+		p.Steps = append(p.Steps, b.copySteps("eq")...)
+		return p
+	}
+	p := &Pipeline{
+		Steps: []*Step{
+			{
+				Name:   "copy",
+				Inputs: []string{"out/*"},
+				Action: func(b *Builder) error {
+					return copyGlob(b.zonePath("out/*"), b.EQPath)
+				},
+			},
+		},
+	}
+	p.Steps = append(p.Steps, b.copySteps("eq")...)
+	return p
+}
+
+// CopyServerPipeline returns the steps that copy map/ files and the zone manifest's server copies to the server path
+func (b *Builder) CopyServerPipeline() *Pipeline {
+	if b.useScript("copy_server.bat") {
+		p := b.scriptPipeline("copy_server.bat")
+		p.Steps = append(p.Steps, b.copySteps("server")...)
+		return p
+	}
+	p := &Pipeline{
+		Steps: []*Step{
+			{
+				Name:   "copymap",
+				Inputs: []string{"map/*.map"},
+				Action: func(b *Builder) error {
+					return copyGlob(b.zonePath("map/*.map"), filepath.Join(b.ServerPath, "base"))
+				},
+			},
+			{
+				Name:   "copywater",
+				Inputs: []string{"map/*.wtr"},
+				Action: func(b *Builder) error {
+					return copyGlob(b.zonePath("map/*.wtr"), filepath.Join(b.ServerPath, "water"))
+				},
+			},
+		},
+	}
+	p.Steps = append(p.Steps, b.copySteps("server")...)
+	return p
+}
+
+// scriptPipeline wraps a zone's .bat file as a single step
+func (b *Builder) scriptPipeline(name string) *Pipeline {
+	return &Pipeline{
+		Steps: []*Step{
+			{
+				Name:      name,
+				Command:   b.zonePath(name),
+				Env:       b.Env(),
+				IsBlender: name == "convert.bat",
+			},
+		},
+	}
+}
+
+// useScript returns true if a customized zone script should be run instead of the native pipeline
+func (b *Builder) useScript(name string) bool {
+	if !b.isCustomScript(name) {
+		return false
+	}
+	if runtime.GOOS != "windows" {
+		b.logf("Ignoring customized %s, .bat scripts only run on windows", name)
+		return false
+	}
+	return true
+}
+
+// isCustomScript returns true if the zone has a script named name that differs from the default one
+func (b *Builder) isCustomScript(name string) bool {
+	data, err := os.ReadFile(b.zonePath(name))
+	if err != nil {
+		return false
+	}
+	stock, ok := b.DefaultFiles[name]
+	if !ok {
+		return true
+	}
+	return normalizeNewlines(data) != normalizeNewlines(stock)
+}
+
+func (b *Builder) zonePath(format string, a ...interface{}) string {
+	return filepath.Join(b.CurrentPath, "zones", b.Zone, fmt.Sprintf(format, a...))
+}
+
+// ToolsPath returns the folder of the eqgzi version the zone builds with
+func (b *Builder) ToolsPath() string {
+	dir, err := tool.New(b.CurrentPath).Dir(tool.EQGZI, b.EQGZIVersion)
+	if err != nil {
+		return filepath.Join(b.CurrentPath, "tools")
+	}
+	return dir
+}
+
+// checkTools returns an error if the eqgzi version the zone builds with is not installed
+func (b *Builder) checkTools() error {
+	m := tool.New(b.CurrentPath)
+	_, err := m.Dir(tool.EQGZI, b.EQGZIVersion)
+	if err != nil {
+		return err
+	}
+	if m.IsInstalled(tool.EQGZI, b.EQGZIVersion) {
+		b.logf("Using eqgzi %s", b.EQGZIVersion)
+	}
+	return nil
+}
+
+// toolExecutable returns the path to a tool, preferring a native build when not on windows
+func (b *Builder) toolExecutable(name string) string {
+	return launch.Executable(b.ToolsPath(), name)
+}
+
+// BlenderExecutable returns the blender binary. BlenderPath may be a folder, a macOS Blender.app or the binary itself
+func (b *Builder) BlenderExecutable() string {
+	name := "blender"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if b.BlenderPath == "" {
+		return name
+	}
+	fi, err := os.Stat(b.BlenderPath)
+	if err == nil && !fi.IsDir() {
+		return b.BlenderPath
+	}
+	if strings.HasSuffix(strings.TrimRight(b.BlenderPath, "/"), ".app") {
+		return filepath.Join(b.BlenderPath, "Contents", "MacOS", "Blender")
+	}
+	return filepath.Join(b.BlenderPath, name)
+}
@@ -0,0 +1,80 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/eqgzi-manager/pfs"
+)
+
+// repackFolders are searched in order for replacements of packed files
+var repackFolders = []string{"", "texture"}
+
+// RepackPipeline returns a step that replaces files inside out/<zone>.eqg with
+// edited copies found in the zone folder, without running Blender or eqgzi
+func (b *Builder) RepackPipeline() *Pipeline {
+	return &Pipeline{
+		Steps: []*Step{
+			{
+				Name:    "repack",
+				Inputs:  []string{fmt.Sprintf("out/%s.eqg", b.Zone)},
+				Outputs: []string{fmt.Sprintf("out/%s.eqg", b.Zone)},
+				Action: func(b *Builder) error {
+					return b.repack()
+				},
+			},
+		},
+	}
+}
+
+// Repack runs the repack pipeline for the zone
+func (b *Builder) Repack(ctx context.Context) error {
+	return b.RunPipeline(ctx, b.RepackPipeline(), "repack.log")
+}
+
+func (b *Builder) repack() error {
+	path := b.zonePath("out/%s.eqg", b.Zone)
+	a, err := pfs.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s.eqg: %w", b.Zone, err)
+	}
+	files, err := a.Files()
+	a.Close()
+	if err != nil {
+		return fmt.Errorf("read %s.eqg: %w", b.Zone, err)
+	}
+
+	count := 0
+	for _, f := range files {
+		err = pfs.CheckName(f.Name)
+		if err != nil {
+			return fmt.Errorf("%s.eqg: %w", b.Zone, err)
+		}
+		for _, folder := range repackFolders {
+			data, err := os.ReadFile(filepath.Join(b.zonePath(folder), f.Name))
+			if err != nil {
+				continue
+			}
+			if !bytes.Equal(data, f.Data) {
+				b.logf("Repacking %s", f.Name)
+				f.Data = data
+				count++
+			}
+			break
+		}
+	}
+	if count == 0 {
+		b.logf("No changed files to repack in %s.eqg", b.Zone)
+		return nil
+	}
+
+	err = pfs.WriteFile(path, files)
+	if err != nil {
+		return fmt.Errorf("write %s.eqg: %w", b.Zone, err)
+	}
+	b.logf("Repacked %d files into %s.eqg", count, b.Zone)
+	return nil
+}
@@ -0,0 +1,81 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/eqmap"
+	"github.com/xackery/eqgzi-manager/pfs"
+	"github.com/xackery/eqgzi-manager/wtr"
+)
+
+// verifyArchive opens out/<zone>.eqg and reports referenced files that were not packed
+func (b *Builder) verifyArchive() error {
+	a, err := pfs.Open(b.zonePath("out/%s.eqg", b.Zone))
+	if err != nil {
+		return fmt.Errorf("open %s.eqg: %w", b.Zone, err)
+	}
+	defer a.Close()
+
+	missing, err := a.MissingReferences()
+	if err != nil {
+		return fmt.Errorf("references of %s.eqg: %w", b.Zone, err)
+	}
+	if len(missing) > 0 {
+		b.logf("Warning: %s.eqg is missing %s", b.Zone, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// verifyMap checks the map azone wrote to out/ and compares it with the previous build in map/
+func (b *Builder) verifyMap() error {
+	m, err := eqmap.Open(b.zonePath("out/%s.map", b.Zone))
+	if err != nil {
+		return fmt.Errorf("decode %s.map: %w", b.Zone, err)
+	}
+	err = m.Validate()
+	if err != nil {
+		return fmt.Errorf("%s.map: %w", b.Zone, err)
+	}
+	b.logf("%s.map has %d vertices and %d faces", b.Zone, m.VertexCount(), m.FaceCount())
+
+	old, err := eqmap.Open(b.zonePath("map/%s.map", b.Zone))
+	if err != nil {
+		return nil
+	}
+	diff := eqmap.Compare(old, m)
+	for _, warning := range diff.Warnings {
+		b.logf("Warning: %s.map %s", b.Zone, warning)
+	}
+	return nil
+}
+
+// ValidateWater checks the regions in wtrPath against the bounding box of the map at mapPath
+func ValidateWater(mapPath string, wtrPath string) (*wtr.File, []wtr.Issue, error) {
+	f, err := wtr.Open(wtrPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode %s: %w", filepath.Base(wtrPath), err)
+	}
+	m, err := eqmap.Open(mapPath)
+	if err != nil {
+		return f, nil, fmt.Errorf("decode %s: %w", filepath.Base(mapPath), err)
+	}
+	// azone writes map vertices with x and y swapped from water regions
+	min := wtr.Vec3{X: m.Min.Y, Y: m.Min.X, Z: m.Min.Z}
+	max := wtr.Vec3{X: m.Max.Y, Y: m.Max.X, Z: m.Max.Z}
+	return f, f.Validate(min, max), nil
+}
+
+// verifyWater reports problems with the regions awater wrote to out/
+func (b *Builder) verifyWater() error {
+	f, issues, err := ValidateWater(b.zonePath("map/%s.map", b.Zone), b.zonePath("out/%s.wtr", b.Zone))
+	if err != nil {
+		return err
+	}
+	b.logf("%s.wtr has %d regions", b.Zone, len(f.Regions))
+	for _, issue := range issues {
+		b.logf("Warning: %s.wtr %s", b.Zone, issue)
+	}
+	return nil
+}
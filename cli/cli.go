@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK    = 0
+	ExitFail  = 1
+	ExitUsage = 2
+	// ExitCancelled is returned when a command is interrupted
+	ExitCancelled = 130
+)
+
+type command struct {
+	name string
+	args string
+	desc string
+	run  func(ctx context.Context, cfg *config.Config, currentPath string, args []string) error
+}
+
+var commands = []command{
+	{"build", "[-eq] [-server] [-force] <zone>", "convert a zone, optionally copying it", runBuild},
+	{"batch", "[-workers n] [-blender n] [-force] <-all | zone...>", "convert several zones at once, writing a summary to batch.log", runBatch},
+	{"watch", "[-eq] [-server] [-debounce 2s] <zone>", "convert a zone whenever its .blend, textures or .txt files change", runWatch},
+	{"history", "<zone> [run] [older run]", "list a zone's builds, show one compared to the build before it, or compare two", runHistory},
+	{"list", "", "list zones", runList},
+	{"new", "[-template name] <zone>", "create a new zone, optionally from a template", runNew},
+	{"templates", "", "list zone templates", runTemplates},
+	{"check-name", "[-server] <zone>", "check a zone name against client rules, stock zones and the server's zone table", runCheckName},
+	{"clone", "<zone> <new zone>", "copy a zone, renaming files named after it", runClone},
+	{"rename", "<zone> <new zone>", "rename a zone and the files named after it", runRename},
+	{"delete", "<zone>", "move a zone to the trash folder", runDelete},
+	{"restore", "[trashed zone]", "list the trash folder, or restore a deleted zone", runRestore},
+	{"manifest", "<zone>", "show a zone's zone.toml build settings", runManifest},
+	{"import", "[-blend] <eqzone> [zone]", "create a zone from an EverQuest zone with LanternExtractor", runImport},
+	{"copy-eq", "<zone>", "copy a converted zone to EverQuest", runCopyEQ},
+	{"copy-server", "<zone>", "copy a zone's nav meshes to the server", runCopyServer},
+	{"inspect", "<zone|file.eqg>", "list the files packed in a zone's .eqg", runInspect},
+	{"repack", "<zone>", "replace edited textures inside a zone's .eqg", runRepack},
+	{"pack", "<out.eqg> <file>...", "pack files into a new .eqg", runPack},
+	{"map-info", "<zone|file.map> [old.map]", "show a collision map, optionally compared to an older build", runMapInfo},
+	{"water", "[-map file.map] <zone|file.wtr>", "list and validate the water regions of a zone", runWater},
+	{"extract", "<zone|file.eqg> <name> [dst]", "extract a file from a zone's .eqg", runExtract},
+	{"tools", "list | install <tool> [version] | pin [-zone z] <version> | rollback [-zone z] [tool] | remove <tool> <version>", "manage eqgzi and lantern versions", runTools},
+	{"blender", "[-use n]", "list the Blender installs found on this machine, or use install n to build with", runBlender},
+	{"cache-clean", "", "remove downloaded tool zips and partial downloads", runCacheClean},
+	{"update", "[-check]", "download and install the newest eqgzi-manager", runUpdate},
+}
+
+// IsCommand returns true if name is a known subcommand
+func IsCommand(name string) bool {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return name == "help" || name == "-h" || name == "--help"
+}
+
+// Run executes a subcommand from args and returns the process exit code
+func Run(version string, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(os.Stdout, version)
+		return ExitOK
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n", args[0])
+		usage(os.Stderr, version)
+		return ExitUsage
+	}
+
+	cfg, err := config.New(context.Background())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config new:", err)
+		return ExitFail
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "wd invalid:", err)
+		return ExitFail
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err = cmd.run(ctx, cfg, currentPath, args[1:])
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "%s cancelled\n", cmd.name)
+			return ExitCancelled
+		}
+		if err == flag.ErrHelp {
+			return ExitUsage
+		}
+		if _, ok := err.(usageError); ok {
+			fmt.Fprintf(os.Stderr, "%s\nusage: eqgzi-manager %s %s\n", err, cmd.name, cmd.args)
+			return ExitUsage
+		}
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", cmd.name, err)
+		return ExitFail
+	}
+	return ExitOK
+}
+
+func usage(w io.Writer, version string) {
+	fmt.Fprintf(w, "eqgzi-manager v%s\n\nusage: eqgzi-manager [command]\n\nRunning without a command opens the manager window.\n\ncommands:\n", version)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.desc)
+	}
+	tw.Flush()
+}
+
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// zoneArg parses flags and returns the zone named by the only positional argument
+func zoneArg(fs *flag.FlagSet, args []string) (string, error) {
+	err := fs.Parse(args)
+	if err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", usageError("expected a zone name")
+	}
+	return strings.ToLower(strings.TrimSpace(fs.Arg(0))), nil
+}
+
+func newBuilder(cfg *config.Config, currentPath string, zoneName string) (*build.Builder, error) {
+	zones, err := zone.List(currentPath)
+	if err != nil {
+		return nil, err
+	}
+	isValidZone := false
+	for _, name := range zones {
+		if name == zoneName {
+			isValidZone = true
+			break
+		}
+	}
+	if !isValidZone {
+		return nil, fmt.Errorf("zone %s not found", zoneName)
+	}
+
+	b := build.New(cfg, currentPath, zoneName)
+	b.Version = resource.Version()
+	b.DefaultFiles = resource.ZoneFiles()
+	b.Logf = func(format string, a ...interface{}) {
+		fmt.Printf(format+"\n", a...)
+	}
+	b.OnEvent = func(e build.Event) {
+		if e.Type == build.EventProgress {
+			return
+		}
+		fmt.Println(e)
+	}
+	return b, nil
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/eqmap"
+	"github.com/xackery/eqgzi-manager/pfs"
+	"github.com/xackery/eqgzi-manager/wtr"
+)
+
+// archivePath returns arg if it is an archive file, otherwise the .eqg of the zone named arg
+func archivePath(currentPath string, arg string) string {
+	if strings.Contains(arg, ".") {
+		return arg
+	}
+	return fmt.Sprintf("%s/zones/%s/out/%s.eqg", currentPath, arg, arg)
+}
+
+func runInspect(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("expected a zone name or .eqg path")
+	}
+	a, err := pfs.Open(archivePath(currentPath, fs.Arg(0)))
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tCOMPRESSED\tCRC")
+	for _, e := range a.Entries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%08x\n", e.Name, e.Size, e.CompressedSize, e.CRC)
+	}
+	w.Flush()
+
+	missing, err := a.MissingReferences()
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing referenced files: %s", strings.Join(missing, ", "))
+	}
+	fmt.Printf("%d files, all references packed\n", len(a.Entries))
+	return nil
+}
+
+func runExtract(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		return usageError("expected a zone name or .eqg path and a file name")
+	}
+	a, err := pfs.Open(archivePath(currentPath, fs.Arg(0)))
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	dst := filepath.Base(fs.Arg(1))
+	if fs.NArg() == 3 {
+		dst = fs.Arg(2)
+	}
+	err = a.Extract(fs.Arg(1), dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Extracted %s to %s\n", fs.Arg(1), dst)
+	return nil
+}
+
+func runRepack(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("repack", flag.ContinueOnError)
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	b, err := newBuilder(cfg, currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	return b.Repack(ctx)
+}
+
+func runPack(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("pack", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return usageError("expected an output path and at least one file")
+	}
+	files := []*pfs.File{}
+	for _, path := range fs.Args()[1:] {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		files = append(files, &pfs.File{Name: filepath.Base(path), Data: data})
+	}
+	err = pfs.WriteFile(fs.Arg(0), files)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Packed %d files into %s\n", len(files), fs.Arg(0))
+	return nil
+}
+
+func runMapInfo(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("map-info", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError("expected a zone name or .map path")
+	}
+	path := fs.Arg(0)
+	if !strings.Contains(path, ".") {
+		path = fmt.Sprintf("%s/zones/%s/map/%s.map", currentPath, path, path)
+	}
+	m, err := eqmap.Open(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\n", m.Version)
+	if m.Version == 2 {
+		fmt.Printf("compressed: %d -> %d bytes\n", m.CompressedSize, m.UncompressedSize)
+	}
+	fmt.Printf("vertices: %d\n", m.VertexCount())
+	fmt.Printf("faces: %d (%d non collidable)\n", m.FaceCount(), m.NonCollideFaceCount())
+	fmt.Printf("models: %d, placeables: %d\n", len(m.Models), len(m.Placeables))
+	fmt.Printf("bounds: (%.1f, %.1f, %.1f)-(%.1f, %.1f, %.1f)\n", m.Min.X, m.Min.Y, m.Min.Z, m.Max.X, m.Max.Y, m.Max.Z)
+
+	if fs.NArg() == 2 {
+		old, err := eqmap.Open(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf("old map: %w", err)
+		}
+		diff := eqmap.Compare(old, m)
+		fmt.Println(diff)
+		if len(diff.Warnings) > 0 {
+			return fmt.Errorf("%d warnings", len(diff.Warnings))
+		}
+	}
+	return m.Validate()
+}
+
+func runWater(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("water", flag.ContinueOnError)
+	mapPath := fs.String("map", "", "collision map to validate regions against")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError("expected a zone name or .wtr path")
+	}
+	wtrPath := fs.Arg(0)
+	if !strings.Contains(wtrPath, ".") {
+		if *mapPath == "" {
+			*mapPath = fmt.Sprintf("%s/zones/%s/map/%s.map", currentPath, wtrPath, wtrPath)
+		}
+		wtrPath = fmt.Sprintf("%s/zones/%s/map/%s.wtr", currentPath, wtrPath, wtrPath)
+	}
+
+	var f *wtr.File
+	issues := []wtr.Issue{}
+	if *mapPath != "" {
+		f, issues, err = build.ValidateWater(*mapPath, wtrPath)
+	} else {
+		f, err = wtr.Open(wtrPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTYPE\tPOSITION\tEXTENTS")
+	for i, r := range f.Regions {
+		fmt.Fprintf(w, "%d\t%s\t(%.1f, %.1f, %.1f)\t(%.1f, %.1f, %.1f)\n", i, r.Type, r.Position.X, r.Position.Y, r.Position.Z, r.Extents.X, r.Extents.Y, r.Extents.Z)
+	}
+	w.Flush()
+
+	errorCount := 0
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if issue.IsError {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d regions have errors", errorCount)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xackery/eqgzi-manager/blender"
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/config"
+)
+
+func runBlender(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("blender", flag.ContinueOnError)
+	use := fs.Int("use", 0, "number of the install to set as the blender path")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError("unexpected arguments")
+	}
+	installs := blender.Detect(ctx)
+	if len(installs) == 0 {
+		return fmt.Errorf("no blender installs found, set blender_path in eqgzi-manager.conf")
+	}
+	if *use > 0 {
+		if *use > len(installs) {
+			return usageError(fmt.Sprintf("expected an install from 1 to %d", len(installs)))
+		}
+		cfg.BlenderPath = installs[*use-1].Path
+		err = cfg.Save()
+		if err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		fmt.Printf("Using %s\n", installs[*use-1])
+		return nil
+	}
+
+	r := build.New(cfg, currentPath, cfg.LastZone).BlenderRange()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tVERSION\tSOURCE\tPATH\t")
+	for i, install := range installs {
+		version := install.Version
+		if version == "" {
+			version = "unknown"
+		}
+		notes := []string{}
+		if !r.Contains(install.Version) {
+			notes = append(notes, "unsupported")
+		}
+		if install.Path == cfg.BlenderPath {
+			notes = append(notes, "current")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, version, install.Source, install.Path, strings.Join(notes, ", "))
+	}
+	fmt.Fprintf(w, "\neqgzi supports blender %s\n", r)
+	return w.Flush()
+}
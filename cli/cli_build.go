@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/watch"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+func runBuild(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	isEQCopy := fs.Bool("eq", cfg.IsEQCopy, "copy .eqg to EverQuest after converting")
+	isServerCopy := fs.Bool("server", cfg.IsServerCopy, "copy nav meshes to server after converting")
+	isForce := fs.Bool("force", false, "rerun every step, even those whose inputs are unchanged")
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	b, err := newBuilder(cfg, currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	b.IsEQCopy = *isEQCopy
+	b.IsServerCopy = *isServerCopy
+	b.IsForceRebuild = *isForce
+	err = b.Run(ctx)
+	printDiagnostics(b.Diagnostics)
+	if err != nil {
+		return err
+	}
+	if len(b.Reused) > 0 {
+		fmt.Printf("Reused unchanged steps: %s\n", strings.Join(b.Reused, ", "))
+	}
+	fmt.Printf("Created %s.eqg\n", zoneName)
+	return nil
+}
+
+func runBatch(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	isAll := fs.Bool("all", false, "convert every zone")
+	workers := fs.Int("workers", cfg.BatchWorkers, "zones converted at once, 0 uses the number of CPUs")
+	maxBlender := fs.Int("blender", cfg.MaxBlender, "most Blender instances run at once, 0 is one")
+	isForce := fs.Bool("force", false, "rerun every step, even those whose inputs are unchanged")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	zones := []string{}
+	for _, arg := range fs.Args() {
+		zones = append(zones, strings.ToLower(strings.TrimSpace(arg)))
+	}
+	if *isAll {
+		if len(zones) > 0 {
+			return usageError("expected -all or zone names, not both")
+		}
+		zones, err = zone.List(currentPath)
+		if err != nil {
+			return err
+		}
+	}
+	if len(zones) == 0 {
+		return usageError("expected -all or zone names")
+	}
+	builders := map[string]*build.Builder{}
+	for _, name := range zones {
+		b, err := newBuilder(cfg, currentPath, name)
+		if err != nil {
+			return err
+		}
+		b.Logf = func(format string, a ...interface{}) {
+			fmt.Printf("%s: %s\n", b.Zone, fmt.Sprintf(format, a...))
+		}
+		b.OnEvent = func(e build.Event) {
+			if e.Type == build.EventProgress {
+				return
+			}
+			fmt.Printf("%s: %s\n", e.Zone, e)
+		}
+		b.IsForceRebuild = *isForce
+		builders[name] = b
+	}
+
+	batch := &build.Batch{
+		Workers:    *workers,
+		MaxBlender: *maxBlender,
+		New: func(name string) *build.Builder {
+			return builders[name]
+		},
+	}
+	results := batch.Run(ctx, zones)
+	fmt.Println()
+	err = build.WriteSummary(os.Stdout, results)
+	if err != nil {
+		return err
+	}
+	err = build.WriteSummaryLog(currentPath, resource.Version(), results)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		if errors.Is(r.Err, context.Canceled) {
+			return r.Err
+		}
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d zones failed", failed, len(results))
+	}
+	return nil
+}
+
+func runWatch(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	isEQCopy := fs.Bool("eq", cfg.IsEQCopy, "copy .eqg to EverQuest after converting")
+	isServerCopy := fs.Bool("server", cfg.IsServerCopy, "copy nav meshes to server after converting")
+	debounce := fs.Duration("debounce", watch.DefaultDebounce, "how long files must stay unchanged before converting")
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	_, err = newBuilder(cfg, currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	w := &watch.Watcher{
+		Dir:      filepath.Join(currentPath, "zones", zoneName),
+		Zone:     zoneName,
+		Debounce: *debounce,
+		OnChange: func(paths []string) bool {
+			fmt.Printf("Changed %s\n", strings.Join(paths, ", "))
+			b, err := newBuilder(cfg, currentPath, zoneName)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "watch:", err)
+				return true
+			}
+			b.IsEQCopy = *isEQCopy
+			b.IsServerCopy = *isServerCopy
+			start := time.Now()
+			err = b.Run(ctx)
+			printDiagnostics(b.Diagnostics)
+			if err != nil {
+				fmt.Printf("Failed %s at %s: %s\n", zoneName, time.Now().Format("15:04:05"), err)
+				return true
+			}
+			fmt.Printf("Created %s.eqg at %s in %s\n", zoneName, time.Now().Format("15:04:05"), time.Since(start).Round(100*time.Millisecond))
+			return true
+		},
+	}
+	fmt.Printf("Watching zones/%s, press Ctrl+C to stop\n", zoneName)
+	err = w.Run(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Stopped watching")
+	return nil
+}
+
+func runHistory(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 3 {
+		return usageError("expected a zone, and optionally one or two runs")
+	}
+	zoneName := strings.ToLower(strings.TrimSpace(fs.Arg(0)))
+	runs, err := build.Runs(currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("%s has no recorded builds", zoneName)
+	}
+
+	if fs.NArg() == 1 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "run\tresult\tduration\tsize\tchange\treused\teqgzi\tblender\n")
+		for i, run := range runs {
+			change := ""
+			previous := build.PreviousRun(runs, i)
+			if previous != nil && run.IsBuilt() {
+				change = build.SizeDelta(previous.Size(), run.Size())
+			}
+			reused := 0
+			for _, step := range run.Steps {
+				if step.Reused {
+					reused++
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d/%d\t%s\t%s\n", run.ID, run.Result, run.Duration.Round(100*time.Millisecond), run.Size(), change, reused, len(run.Steps), run.EQGZIVersion, run.BlenderVersion)
+		}
+		return w.Flush()
+	}
+
+	run, err := build.FindRun(currentPath, zoneName, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	var previous *build.Run
+	if fs.NArg() == 3 {
+		previous, err = build.FindRun(currentPath, zoneName, fs.Arg(2))
+		if err != nil {
+			return err
+		}
+	} else {
+		for i := range runs {
+			if runs[i].ID == run.ID {
+				previous = build.PreviousRun(runs, i)
+			}
+		}
+	}
+	fmt.Println(run.Details(previous))
+	fmt.Printf("\nLogs are kept in %s\n", run.Dir())
+	return nil
+}
+
+// printDiagnostics writes build problems with their fixes to stderr
+func printDiagnostics(diagnostics []*build.Diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s: [%s] %s\n", d.Rule.Severity, d.Step, d)
+		if d.Rule.Fix != "" {
+			fmt.Fprintf(os.Stderr, "  fix: %s\n", d.Rule.Fix)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/xackery/eqgzi-manager/config"
+)
+
+func runCopyEQ(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("copy-eq", flag.ContinueOnError)
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if cfg.EQPath == "" {
+		return fmt.Errorf("eq_path is not set in eqgzi-manager.conf")
+	}
+	b, err := newBuilder(cfg, currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	return b.CopyEQ(ctx)
+}
+
+func runCopyServer(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("copy-server", flag.ContinueOnError)
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if cfg.ServerPath == "" {
+		return fmt.Errorf("server_path is not set in eqgzi-manager.conf")
+	}
+	b, err := newBuilder(cfg, currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	return b.CopyServer(ctx)
+}
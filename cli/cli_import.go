@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+func runImport(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	isBlend := fs.Bool("blend", false, "import the extracted model into the zone .blend with Blender")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError("expected an EverQuest zone and optional new zone name")
+	}
+	if cfg.EQPath == "" {
+		return fmt.Errorf("eq_path is not set in eqgzi-manager.conf")
+	}
+	eqZone := strings.ToLower(strings.TrimSpace(fs.Arg(0)))
+	zoneName := eqZone
+	if fs.NArg() == 2 {
+		zoneName = strings.ToLower(strings.TrimSpace(fs.Arg(1)))
+	}
+
+	err = checkName(ctx, cfg, zoneName)
+	if err != nil {
+		return err
+	}
+	err = zone.Create(currentPath, zoneName, resource.ZoneFiles())
+	if err != nil {
+		return err
+	}
+	b, err := newBuilder(cfg, currentPath, zoneName)
+	if err == nil {
+		err = b.Import(ctx, eqZone, *isBlend)
+		printDiagnostics(b.Diagnostics)
+	}
+	if err != nil {
+		// the half-created zone goes to the trash so the name can be imported again, keeping any import.log
+		trashName, trashErr := zone.Delete(currentPath, zoneName)
+		if trashErr != nil {
+			return fmt.Errorf("%w, see zones/%s/import.log", err, zoneName)
+		}
+		return fmt.Errorf("%w, moved the unfinished zone to trash/%s", err, trashName)
+	}
+	fmt.Printf("Created zones/%s from %s\n", zoneName, eqZone)
+	return nil
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/release"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/selfupdate"
+	"github.com/xackery/eqgzi-manager/tool"
+)
+
+func runCacheClean(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("cache-clean", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	count, size, err := tool.New(currentPath).CleanCache()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d files, %d bytes\n", count, size)
+	return nil
+}
+
+func runUpdate(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	isCheck := fs.Bool("check", false, "only report if an update is available")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usageError("unexpected arguments")
+	}
+
+	src, err := release.New(cfg.ReleaseSource, cfg.ReleaseURL)
+	if err != nil {
+		return err
+	}
+	u := &selfupdate.Updater{
+		Current: resource.Version(),
+		Source:  src,
+	}
+	up, err := u.Check(ctx)
+	if err != nil {
+		return err
+	}
+	if up == nil {
+		fmt.Printf("eqgzi-manager v%s is up to date\n", u.Current)
+		return nil
+	}
+	if *isCheck {
+		fmt.Printf("eqgzi-manager v%s is available, running v%s\n", up.Version, u.Current)
+		return nil
+	}
+	fmt.Printf("Downloading eqgzi-manager v%s\n", up.Version)
+	path, err := u.Install(ctx, up)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed v%s to %s\n", up.Version, path)
+	return nil
+}
+
+func runTools(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	if len(args) == 0 {
+		return usageError("expected a tools command")
+	}
+	src, err := release.New(cfg.ReleaseSource, cfg.ReleaseURL)
+	if err != nil {
+		return err
+	}
+	m := tool.New(currentPath)
+	m.Source = src
+	fs := flag.NewFlagSet("tools "+args[0], flag.ContinueOnError)
+	zoneName := fs.String("zone", "", "pin or roll back only this zone")
+	err = fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		for _, name := range []string{tool.EQGZI, tool.Lantern} {
+			versions, err := m.Versions(name)
+			if err != nil {
+				return err
+			}
+			active := cfg.LanternVersion
+			if name == tool.EQGZI {
+				active = cfg.EQGZIVersionFor("")
+			}
+			if len(versions) == 0 {
+				_, err = m.Dir(name, "")
+				if err == nil {
+					fmt.Printf("%s %s (legacy tools folder)\n", name, active)
+				}
+				continue
+			}
+			for _, version := range versions {
+				mark := " "
+				if version == active {
+					mark = "*"
+				}
+				fmt.Printf("%s %s %s\n", mark, name, version)
+			}
+		}
+		if cfg.EQGZIPin != "" {
+			fmt.Printf("workspace pinned to eqgzi %s\n", cfg.EQGZIPin)
+		}
+		for zoneName, version := range cfg.ZoneEQGZIPins {
+			fmt.Printf("%s pinned to eqgzi %s\n", zoneName, version)
+		}
+		return nil
+	case "install":
+		if fs.NArg() < 1 || fs.NArg() > 2 {
+			return usageError("expected a tool and optional version")
+		}
+		name := fs.Arg(0)
+		version, err := m.Download(ctx, name, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		switch name {
+		case tool.EQGZI:
+			cfg.EQGZIVersion = version
+		case tool.Lantern:
+			cfg.LanternVersion = version
+		}
+		fmt.Printf("Installed %s %s\n", name, version)
+		return cfg.Save()
+	case "pin":
+		if fs.NArg() != 1 {
+			return usageError("expected an eqgzi version, or - to unpin")
+		}
+		version := fs.Arg(0)
+		if version == "-" {
+			version = ""
+		}
+		if version != "" && !m.IsInstalled(tool.EQGZI, version) {
+			return fmt.Errorf("eqgzi %s is not installed", version)
+		}
+		if *zoneName != "" {
+			cfg.SetZoneEQGZIPin(*zoneName, version)
+		} else {
+			cfg.EQGZIPin = version
+		}
+		return cfg.Save()
+	case "rollback":
+		name := tool.EQGZI
+		if fs.NArg() == 1 {
+			name = fs.Arg(0)
+		}
+		if fs.NArg() > 1 {
+			return usageError("expected an optional tool")
+		}
+		current := cfg.LanternVersion
+		if name == tool.EQGZI {
+			current = cfg.EQGZIVersionFor(*zoneName)
+		}
+		previous, err := m.Previous(name, current)
+		if err != nil {
+			return err
+		}
+		switch {
+		case name == tool.Lantern:
+			cfg.LanternVersion = previous
+		case *zoneName != "":
+			cfg.SetZoneEQGZIPin(*zoneName, previous)
+		default:
+			cfg.EQGZIPin = previous
+		}
+		fmt.Printf("Rolled %s back from %s to %s\n", name, current, previous)
+		return cfg.Save()
+	case "remove":
+		if fs.NArg() != 2 {
+			return usageError("expected a tool and version")
+		}
+		return m.Remove(fs.Arg(0), fs.Arg(1))
+	}
+	return usageError(fmt.Sprintf("unknown tools command %s", args[0]))
+}
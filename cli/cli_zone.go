@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+func runList(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	zones, err := zone.List(currentPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range zones {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func runNew(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	templateName := fs.String("template", zone.DefaultTemplate, "template to create the zone from")
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	t, err := zone.FindTemplate(currentPath, *templateName, resource.ZoneFiles())
+	if err != nil {
+		return err
+	}
+	err = checkName(ctx, cfg, zoneName)
+	if err != nil {
+		return err
+	}
+	err = t.Create(currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created zones/%s from the %s template\n", zoneName, t.Name)
+	return nil
+}
+
+func runTemplates(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("templates", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	templates, err := zone.Templates(currentPath, resource.ZoneFiles())
+	if err != nil {
+		return err
+	}
+	for _, t := range templates {
+		path := "built in"
+		if t.Path != "" {
+			path = t.Path
+		}
+		fmt.Printf("%s\t%s\n", t.Name, path)
+	}
+	return nil
+}
+
+// checkName returns an error if zoneName breaks the client's rules, printing any warnings
+func checkName(ctx context.Context, cfg *config.Config, zoneName string) error {
+	warnings, err := zone.CheckName(ctx, zoneName, cfg.ZoneCheckPath())
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	return nil
+}
+
+func runCheckName(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("check-name", flag.ContinueOnError)
+	isServer := fs.Bool("server", cfg.IsServerZoneCheck, "check the server's zone table")
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	serverPath := ""
+	if *isServer {
+		if cfg.ServerPath == "" {
+			return fmt.Errorf("server_path is not set in eqgzi-manager.conf")
+		}
+		serverPath = cfg.ServerPath
+	}
+	warnings, err := zone.CheckName(ctx, zoneName, serverPath)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Println(warning)
+	}
+	fmt.Printf("%s is a valid zone name\n", zoneName)
+	return nil
+}
+
+// zonePairArg parses flags and returns the two zone names given as positional arguments
+func zonePairArg(fs *flag.FlagSet, args []string) (string, string, error) {
+	err := fs.Parse(args)
+	if err != nil {
+		return "", "", err
+	}
+	if fs.NArg() != 2 {
+		return "", "", usageError("expected a zone and a new zone name")
+	}
+	return strings.ToLower(strings.TrimSpace(fs.Arg(0))), strings.ToLower(strings.TrimSpace(fs.Arg(1))), nil
+}
+
+func runClone(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	src, dst, err := zonePairArg(flag.NewFlagSet("clone", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	err = checkName(ctx, cfg, dst)
+	if err != nil {
+		return err
+	}
+	err = zone.Clone(currentPath, src, dst)
+	if err != nil {
+		return err
+	}
+	version, ok := cfg.ZoneEQGZIPins[src]
+	if ok {
+		cfg.SetZoneEQGZIPin(dst, version)
+		err = cfg.Save()
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Cloned %s to zones/%s\n", src, dst)
+	return nil
+}
+
+func runRename(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	oldName, newName, err := zonePairArg(flag.NewFlagSet("rename", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	err = checkName(ctx, cfg, newName)
+	if err != nil {
+		return err
+	}
+	err = zone.Rename(currentPath, oldName, newName)
+	if err != nil {
+		return err
+	}
+	cfg.RenameZone(oldName, newName)
+	err = cfg.Save()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Renamed %s to %s\n", oldName, newName)
+	return nil
+}
+
+func runDelete(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	trashName, err := zone.Delete(currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	cfg.RemoveZone(zoneName)
+	err = cfg.Save()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Moved %s to trash/%s\n", zoneName, trashName)
+	return nil
+}
+
+func runRestore(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		names, err := zone.Trash(currentPath)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	}
+	if fs.NArg() != 1 {
+		return usageError("expected a trashed zone")
+	}
+	name, err := zone.Restore(currentPath, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored zones/%s\n", name)
+	return nil
+}
+
+func runManifest(ctx context.Context, cfg *config.Config, currentPath string, args []string) error {
+	fs := flag.NewFlagSet("manifest", flag.ContinueOnError)
+	zoneName, err := zoneArg(fs, args)
+	if err != nil {
+		return err
+	}
+	b, err := newBuilder(cfg, currentPath, zoneName)
+	if err != nil {
+		return err
+	}
+	p, err := b.ZonePipeline()
+	if err != nil {
+		return err
+	}
+	m := b.Manifest
+	if m == nil {
+		fmt.Printf("zones/%s has no %s, using defaults\n", zoneName, zone.ManifestName)
+		m = &zone.Manifest{}
+	}
+	steps := []string{}
+	for _, step := range p.Steps {
+		steps = append(steps, step.Name)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "long name\t%s\n", m.LongName)
+	fmt.Fprintf(w, "zone id\t%d\n", m.ZoneID)
+	fmt.Fprintf(w, "blender\t%s\n", b.BlenderPath)
+	fmt.Fprintf(w, "eqgzi\t%s\n", b.EQGZIVersion)
+	fmt.Fprintf(w, "steps\t%s\n", strings.Join(steps, ", "))
+	for _, path := range m.ExtraOutputs {
+		fmt.Fprintf(w, "extra output\t%s\n", path)
+	}
+	for _, c := range m.Copies {
+		fmt.Fprintf(w, "copy\t%s -> %s\n", c.Files, c.Target)
+	}
+	return w.Flush()
+}
@@ -4,18 +4,22 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/xackery/eqgzi-manager/build"
 	"github.com/xackery/eqgzi-manager/config"
+	"github.com/xackery/eqgzi-manager/launch"
+	"github.com/xackery/eqgzi-manager/release"
+	"github.com/xackery/eqgzi-manager/selfupdate"
+	"github.com/xackery/eqgzi-manager/tool"
+	"github.com/xackery/eqgzi-manager/zone"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -36,6 +40,7 @@ type Client struct {
 	newZoneButton         *widget.Button
 	newZonePopup          *widget.PopUp
 	newZoneName           *widget.Entry
+	newZoneTemplate       *widget.Select
 	newZoneSaveButton     *widget.Button
 	newZoneCancelButton   *widget.Button
 	popupStatus           *widget.Label
@@ -43,6 +48,7 @@ type Client struct {
 	setServerButton       *widget.Button
 	setServerPopup        *widget.PopUp
 	setServerName         *widget.Entry
+	setServerZoneCheck    *widget.Check
 	setServerSaveButton   *widget.Button
 	setServerCancelButton *widget.Button
 	labelServer           *widget.Label
@@ -61,10 +67,26 @@ type Client struct {
 	folderOpenButton      *widget.Button
 	eqgziOpenButton       *widget.Button
 	convertButton         *widget.Button
+	stopButton            *widget.Button
+	buildCancel           context.CancelFunc
+	diagnosticsButton     *widget.Button
+	diagnostics           []*build.Diagnostic
+	inspectButton         *widget.Button
+	waterButton           *widget.Button
 	downloadEQGZIButton   *widget.Button
 	blenderDetectButton   *widget.Button
 	navMeshEditButton     *widget.Button
 	downloadButton        *widget.Button
+	toolsButton           *widget.Button
+	importButton          *widget.Button
+	manifestButton        *widget.Button
+	batchButton           *widget.Button
+	forceRebuildCheck     *widget.Check
+	watchCheck            *widget.Check
+	watchIcon             *widget.Icon
+	watchLabel            *widget.Label
+	watchCancel           context.CancelFunc
+	historyButton         *widget.Button
 }
 
 func New(window fyne.Window) (*Client, error) {
@@ -82,6 +104,7 @@ func New(window fyne.Window) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("wd invalid: %w", err)
 	}
+	selfupdate.Cleanup()
 
 	//c.currentPath = `C:\src\eqp\client\zones`
 
@@ -93,14 +116,31 @@ func New(window fyne.Window) (*Client, error) {
 	c.popupStatus.Wrapping = fyne.TextWrapBreak
 	c.popupStatus.Alignment = fyne.TextAlignCenter
 	c.newZoneInit()
+	c.importButton = widget.NewButtonWithIcon("Import from EverQuest", theme.DownloadIcon(), c.onImportButton)
 	c.newSetServerInit()
 	c.newSetEQInit()
 
 	c.downloadButton = widget.NewButtonWithIcon("Download Update", theme.DownloadIcon(), c.onDownloadButton)
+	c.toolsButton = widget.NewButtonWithIcon("Tool versions", theme.SettingsIcon(), c.onToolsButton)
 
 	c.convertButton = widget.NewButtonWithIcon("Create zone.eqg", theme.NewThemedResource(eqIcon), c.onConvertButton)
+	c.forceRebuildCheck = widget.NewCheck("Force full rebuild", nil)
+	c.watchCheck = widget.NewCheck("Rebuild on save", c.onWatchCheck)
+	c.watchIcon = widget.NewIcon(theme.VisibilityIcon())
+	c.watchIcon.Hide()
+	c.watchLabel = widget.NewLabel("")
+	c.watchLabel.Hide()
+	c.batchButton = widget.NewButtonWithIcon("Batch build", theme.ListIcon(), c.onBatchButton)
+	c.stopButton = widget.NewButtonWithIcon("Stop", theme.MediaStopIcon(), c.onStopButton)
+	c.stopButton.Hide()
+	c.diagnosticsButton = widget.NewButtonWithIcon("Build problems", theme.WarningIcon(), c.onDiagnosticsButton)
+	c.diagnosticsButton.Hide()
+	c.historyButton = widget.NewButtonWithIcon("Build history", theme.HistoryIcon(), c.onHistoryButton)
+	c.inspectButton = widget.NewButtonWithIcon("Inspect zone.eqg", theme.ListIcon(), c.onInspectButton)
+	c.waterButton = widget.NewButtonWithIcon("Water regions", theme.ColorPaletteIcon(), c.onWaterButton)
 	c.blenderOpenButton = widget.NewButtonWithIcon("Open zone in blender", theme.NewThemedResource(blenderIcon), c.onBlenderOpen)
 	c.folderOpenButton = widget.NewButtonWithIcon("Open zone folder", theme.FolderOpenIcon(), c.onFolderOpen)
+	c.manifestButton = widget.NewButtonWithIcon("Zone settings", theme.DocumentIcon(), c.onManifestButton)
 	c.eqgziOpenButton = widget.NewButtonWithIcon("Debug zone in eqgzi-gui", theme.QuestionIcon(), c.onEqgziOpenButton)
 	c.downloadEQGZIButton = widget.NewButtonWithIcon("Download EQGZI & Lantern", theme.DownloadIcon(), c.onDownloadEQGZIButton)
 	c.navMeshEditButton = widget.NewButtonWithIcon("Edit Navmesh", theme.GridIcon(), c.onNavMeshEditButton)
@@ -137,6 +177,9 @@ func New(window fyne.Window) (*Client, error) {
 	c.zoneCombo = widget.NewSelect(zones, c.onZoneCombo)
 
 	zoneRefreshButton := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), c.onZoneRefresh)
+	zoneCloneButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), c.onZoneCloneButton)
+	zoneRenameButton := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), c.onZoneRenameButton)
+	zoneDeleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), c.onZoneDeleteButton)
 
 	c.progressBar = widget.NewProgressBar()
 	c.progressBar.Hide()
@@ -163,6 +206,7 @@ func New(window fyne.Window) (*Client, error) {
 
 	c.mainCanvas = container.NewVBox(
 		c.downloadButton,
+		c.toolsButton,
 		widget.NewLabel(""),
 		container.NewVBox(
 			container.New(
@@ -183,14 +227,19 @@ func New(window fyne.Window) (*Client, error) {
 		),
 		widget.NewLabel(""),
 		c.newZoneButton,
+		c.importButton,
 		container.NewCenter(
 			container.NewHBox(
 				widget.NewLabel("Zone: "),
 				c.zoneCombo,
 				zoneRefreshButton,
+				zoneCloneButton,
+				zoneRenameButton,
+				zoneDeleteButton,
 			),
 		),
 		container.NewVBox(
+			c.manifestButton,
 			c.folderOpenButton,
 			c.blenderOpenButton,
 			container.NewHBox(
@@ -203,12 +252,18 @@ func New(window fyne.Window) (*Client, error) {
 				c.setServerButton,
 				c.labelServer,
 			),
-			c.convertButton,
+			container.NewBorder(nil, nil, nil, c.forceRebuildCheck, c.convertButton),
+			container.NewHBox(c.watchCheck, c.watchIcon, c.watchLabel),
+			c.batchButton,
+			c.historyButton,
+			c.inspectButton,
+			c.waterButton,
 			c.eqgziOpenButton,
 			c.navMeshEditButton,
 		),
-		c.progressBar,
+		container.NewBorder(nil, nil, nil, c.stopButton, c.progressBar),
 		c.statusLabel,
+		c.diagnosticsButton,
 	)
 
 	c.downloadCanvas = container.NewVBox(
@@ -217,11 +272,12 @@ func New(window fyne.Window) (*Client, error) {
 		c.statusLabel,
 	)
 
-	_, err = os.Stat("tools/eqgzi.exe")
+	m := tool.New(c.currentPath)
+	_, err = m.Dir(tool.EQGZI, "")
 	if err != nil {
 		c.canvas = c.downloadCanvas
 	} else {
-		_, err = os.Stat("tools/LanternExtractor.exe")
+		_, err = m.Dir(tool.Lantern, "")
 		if err != nil {
 			c.canvas = c.downloadCanvas
 		} else {
@@ -249,33 +305,14 @@ func (c *Client) onZoneRefresh() {
 }
 
 func (c *Client) zoneRefresh() []string {
-	zones := []string{}
 	c.mu.RLock()
 	currentPath := c.currentPath
 	c.mu.RUnlock()
 
-	_, err := os.Stat("zones")
-	if os.IsNotExist(err) {
-		err = os.Mkdir("zones", os.ModePerm)
-		if err != nil {
-			c.logf("Failed to mkdir zone: %s", err)
-			return zones
-		}
-	}
-
-	entries, err := os.ReadDir(fmt.Sprintf("%s/zones/", currentPath))
+	zones, err := zone.List(currentPath)
 	if err != nil {
-		c.logf("Failed to read dir: %s", err)
-		return zones
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		zones = append(zones, filepath.Base(entry.Name()))
+		c.logf("Failed to list zones: %s", err)
 	}
-
 	return zones
 }
 
@@ -283,20 +320,26 @@ func (c *Client) onBlenderOpen() {
 	c.mu.RLock()
 	currentPath := c.currentPath
 	zone := c.cfg.LastZone
-	blenderPath := c.cfg.BlenderPath
+	b := build.New(c.cfg, currentPath, zone)
 	c.mu.RUnlock()
 
 	c.logf("Opening %s in Blender", zone)
-	cmd := c.createCommand(false, blenderPath+"blender.exe", fmt.Sprintf("%s/zones/%s/%s.blend", currentPath, zone, zone))
-
-	//cmd.Dir = fmt.Sprintf("%s/", blenderPath)
+	blenderPath := b.BlenderExecutable()
+	cmd := c.createCommand(false, blenderPath, filepath.Join(currentPath, "zones", zone, zone+".blend"))
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Env = []string{}
-	err := cmd.Start()
+	err := c.startCommand(cmd, blenderPath)
 	if err != nil {
 		c.logf("Failed to run blender: %s", err)
+		return
 	}
+
+	go func() {
+		version, err := b.CheckBlender(context.Background())
+		if version != "" && err != nil {
+			c.logf("Warning: %s", err)
+		}
+	}()
 }
 
 func (c *Client) onFolderOpen() {
@@ -305,21 +348,20 @@ func (c *Client) onFolderOpen() {
 	zone := c.cfg.LastZone
 	c.mu.RUnlock()
 
-	exePath := "explorer.exe"
-	if runtime.GOOS == "darwin" {
-		exePath = "open"
+	cmd, err := launch.OpenCommand(filepath.Join(currentPath, "zones", zone))
+	if err != nil {
+		c.logf("Failed to open: %s", err)
+		return
 	}
-
-	cmd := c.createCommand(false, exePath, fmt.Sprintf(`%s\zones\%s`, currentPath, zone))
-	//cmd.Dir = fmt.Sprintf("%s/", blenderPath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Env = []string{}
-	err := cmd.Start()
+	err = cmd.Start()
 	if err != nil {
 		c.logf("Failed to open: %s", err)
 		return
 	}
+	// explorer.exe exits with 1 even when it opened the folder, so only reap the process
+	go cmd.Wait()
 	c.logf("Opened %s folder", zone)
 }
 
@@ -328,15 +370,20 @@ func (c *Client) onZoneCombo(value string) {
 	c.cfg.LastZone = value
 	err := c.cfg.Save()
 	if err != nil {
+		c.mu.Unlock()
 		c.logf("Failed saving after zone select: %s", err)
 		return
 	}
 	c.blenderOpenButton.SetText(fmt.Sprintf("Open %s in Blender", c.cfg.LastZone))
 	c.convertButton.SetText(fmt.Sprintf("Create %s.eqg", c.cfg.LastZone))
+	c.inspectButton.SetText(fmt.Sprintf("Inspect %s.eqg", c.cfg.LastZone))
 	c.folderOpenButton.SetText(fmt.Sprintf("Open %s folder", c.cfg.LastZone))
 	c.eqgziOpenButton.SetText(fmt.Sprintf("Debug %s in eqgzi-gui", c.cfg.LastZone))
 	c.enableActions()
 	c.mu.Unlock()
+	c.manifestRefresh()
+	// watching follows a single zone
+	c.watchCheck.SetChecked(false)
 	c.logf("Focused on %s", value)
 }
 
@@ -376,9 +423,15 @@ func (c *Client) onEqgziOpenButton() {
 	c.mu.RLock()
 	currentPath := c.currentPath
 	zone := c.cfg.LastZone
+	toolsPath := build.New(c.cfg, currentPath, zone).ToolsPath()
 	c.mu.RUnlock()
+	// installs from before versioned tools keep eqgzi-gui in the flat tools folder
+	_, err := os.Stat(filepath.Join(toolsPath, "gui"))
+	if err != nil {
+		toolsPath = filepath.Join(currentPath, "tools")
+	}
 
-	path := fmt.Sprintf("%s/tools/gui/settings.lua", currentPath)
+	path := filepath.Join(toolsPath, "gui", "settings.lua")
 	settings, err := os.ReadFile(path)
 	if err != nil {
 		c.logf("Failed to read settings.lua: %s", err)
@@ -414,11 +467,12 @@ func (c *Client) onEqgziOpenButton() {
 		}
 	}
 
-	cmd := c.createCommand(false, fmt.Sprintf("%s/tools/eqgzi-gui.exe", currentPath), fmt.Sprintf("%s/zones/%s/out/%s.eqg", currentPath, zone, zone))
-	cmd.Dir = fmt.Sprintf("%s/tools/", currentPath)
+	guiPath := launch.Executable(toolsPath, "eqgzi-gui")
+	cmd := c.createCommand(false, guiPath, filepath.Join(currentPath, "zones", zone, "out", zone+".eqg"))
+	cmd.Dir = toolsPath
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	err = cmd.Run()
+	err = c.startCommand(cmd, guiPath)
 	if err != nil {
 		c.logf("Failed eqgzi-gui: %s", err)
 		return
@@ -432,19 +486,29 @@ func (c *Client) logf(format string, a ...interface{}) {
 }
 
 func (c *Client) disableActions() {
+	c.manifestButton.Disable()
+	c.historyButton.Disable()
+	c.watchCheck.Disable()
 	c.blenderOpenButton.Disable()
 	c.folderOpenButton.Disable()
 	c.eqgziOpenButton.Disable()
 	c.convertButton.Disable()
+	c.inspectButton.Disable()
+	c.waterButton.Disable()
 	c.exportEQGCheck.Disable()
 	c.exportServerCheck.Disable()
 }
 
 func (c *Client) enableActions() {
+	c.manifestButton.Enable()
+	c.historyButton.Enable()
+	c.watchCheck.Enable()
 	c.blenderOpenButton.Enable()
 	c.folderOpenButton.Enable()
 	c.eqgziOpenButton.Enable()
 	c.convertButton.Enable()
+	c.inspectButton.Enable()
+	c.waterButton.Enable()
 	c.exportEQGCheck.Enable()
 	c.exportServerCheck.Enable()
 }
@@ -455,18 +519,42 @@ func (c *Client) onNavMeshEditButton() {
 	zone := c.cfg.LastZone
 	c.mu.RUnlock()
 
-	cmd := c.createCommand(false, fmt.Sprintf("%s/tools/map_edit/map_edit.exe", currentPath), zone)
+	dir := filepath.Join(currentPath, "tools", "map_edit")
+	editPath := launch.Executable(dir, "map_edit")
+	cmd := c.createCommand(false, editPath, zone)
 	c.logf("running command: map_edit %s", zone)
-	cmd.Dir = fmt.Sprintf("%s/tools/map_edit/", currentPath)
+	cmd.Dir = dir
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	err := cmd.Run()
+	err := c.startCommand(cmd, editPath)
 	if err != nil {
 		c.logf("Failed map-edit: %s", err)
 		return
 	}
 }
 
+// launcher returns the launcher that starts programs for the buttons
+func (c *Client) launcher() *launch.Launcher {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return &launch.Launcher{WinePath: c.cfg.WinePath}
+}
+
+// startCommand starts cmd running the program at path without waiting on it, logging a failed exit
+func (c *Client) startCommand(cmd *exec.Cmd, path string) error {
+	err := cmd.Start()
+	if err != nil {
+		return c.launcher().Error(path, err)
+	}
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			c.logf("%s exited: %s", filepath.Base(path), err)
+		}
+	}()
+	return nil
+}
+
 func (c *Client) addProgress(amount float64) float64 {
 	c.progress += amount
 
@@ -492,51 +580,50 @@ func (c *Client) loop() {
 }
 
 func (c *Client) updateCheck() error {
-	err := c.updateCheckLantern()
+	// a failed self update check shouldn't keep lantern from being checked
+	err := c.updateCheckSelf()
+	if err != nil {
+		fmt.Println("Failed updateCheckSelf:", err)
+	}
+	err = c.updateCheckLantern()
 	if err != nil {
 		return fmt.Errorf("updateCheckLantern: %w", err)
 	}
 	return nil
 }
 
-func (c *Client) updateCheckLantern() error {
-	c.mu.Lock()
-	lanternVersion := c.cfg.LanternVersion
-	c.mu.Unlock()
-
-	gitReply := &gitReply{}
-	req, err := http.NewRequest("GET", "https://api.github.com/repos/LanternEQ/LanternExtractor/releases/latest", nil)
+func (c *Client) updateCheckSelf() error {
+	u, err := c.newUpdater()
 	if err != nil {
-		return fmt.Errorf("git request: %w", err)
+		return err
 	}
-
-	client := http.DefaultClient
-	resp, err := client.Do(req)
+	up, err := u.Check(context.Background())
 	if err != nil {
-		return fmt.Errorf("do git request: %w", err)
+		return err
 	}
-
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(gitReply)
-	resp.Body.Close()
-	if err != nil {
-		return fmt.Errorf("decode git request: %w", err)
+	if up == nil {
+		return nil
 	}
-	assetURL := ""
+	c.downloadButton.SetText(fmt.Sprintf("Update to v%s", up.Version))
+	return nil
+}
 
-	zipName := fmt.Sprintf("LanternExtractor-%s.zip", gitReply.TagName)
-	for _, asset := range gitReply.Assets {
-		if asset.Name != zipName {
-			continue
-		}
-		assetURL = asset.BrowserDownloadURL
+func (c *Client) updateCheckLantern() error {
+	c.mu.RLock()
+	lanternVersion := c.cfg.LanternVersion
+	c.mu.RUnlock()
+
+	m, err := c.toolManager()
+	if err != nil {
+		return err
 	}
-	if assetURL == "" {
-		return fmt.Errorf("download eqgzi zip not found")
+	latest, err := m.Latest(context.Background(), tool.Lantern)
+	if err != nil {
+		return err
 	}
-
-	if gitReply.TagName == lanternVersion {
+	if release.CompareVersions(latest, lanternVersion) <= 0 || m.IsInstalled(tool.Lantern, latest) {
 		return nil
 	}
+	c.logf("LanternExtractor %s is available, download it from Tool versions", latest)
 	return nil
 }
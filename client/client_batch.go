@@ -0,0 +1,279 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/resource"
+)
+
+// batchRow is a line of the batch summary table
+type batchRow struct {
+	zone    string
+	status  string
+	elapsed time.Duration
+}
+
+func (c *Client) onBatchButton() {
+	zones := c.zoneRefresh()
+	if len(zones) == 0 {
+		c.logf("No zones to build")
+		return
+	}
+	c.mu.RLock()
+	workers := c.cfg.BatchWorkers
+	maxBlender := c.cfg.MaxBlender
+	c.mu.RUnlock()
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapBreak
+	zoneGroup := widget.NewCheckGroup(zones, nil)
+	zoneGroup.SetSelected(zones)
+	allButton := widget.NewButton("All", func() { zoneGroup.SetSelected(zones) })
+	noneButton := widget.NewButton("None", func() { zoneGroup.SetSelected(nil) })
+	workersEntry := widget.NewEntry()
+	workersEntry.SetPlaceHolder("number of CPUs")
+	if workers > 0 {
+		workersEntry.SetText(strconv.Itoa(workers))
+	}
+	forceCheck := widget.NewCheck("Force full rebuild", nil)
+	forceCheck.SetChecked(c.forceRebuildCheck.Checked)
+	blenderEntry := widget.NewEntry()
+	blenderEntry.SetPlaceHolder("1")
+	if maxBlender > 0 {
+		blenderEntry.SetText(strconv.Itoa(maxBlender))
+	}
+
+	rowMu := sync.Mutex{}
+	rows := []*batchRow{}
+	table := widget.NewTable(func() (int, int) {
+		rowMu.Lock()
+		defer rowMu.Unlock()
+		return len(rows) + 1, 3
+	}, func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}, func(id widget.TableCellID, o fyne.CanvasObject) {
+		label := o.(*widget.Label)
+		if id.Row == 0 {
+			label.SetText([]string{"Zone", "Result", "Duration"}[id.Col])
+			label.TextStyle.Bold = true
+			return
+		}
+		label.TextStyle.Bold = false
+		rowMu.Lock()
+		row := rows[id.Row-1]
+		rowMu.Unlock()
+		switch id.Col {
+		case 0:
+			label.SetText(row.zone)
+		case 1:
+			label.SetText(row.status)
+		case 2:
+			text := ""
+			if row.elapsed > 0 {
+				text = row.elapsed.Round(100 * time.Millisecond).String()
+			}
+			label.SetText(text)
+		}
+	})
+	table.SetColumnWidth(0, 160)
+	table.SetColumnWidth(1, 320)
+	table.SetColumnWidth(2, 80)
+	setRow := func(zone string, status string, elapsed time.Duration) {
+		rowMu.Lock()
+		for _, row := range rows {
+			if row.zone == zone {
+				row.status = status
+				row.elapsed = elapsed
+			}
+		}
+		rowMu.Unlock()
+		table.Refresh()
+	}
+
+	zoneScroll := container.NewVScroll(zoneGroup)
+	zoneScroll.SetMinSize(fyne.NewSize(0, 120))
+
+	var popup *widget.PopUp
+	var buildButton, stopButton, closeButton *widget.Button
+	buildButton = widget.NewButtonWithIcon("Build", theme.MediaPlayIcon(), func() {
+		selected := []string{}
+		for _, zone := range zones {
+			for _, name := range zoneGroup.Selected {
+				if name == zone {
+					selected = append(selected, zone)
+				}
+			}
+		}
+		if len(selected) == 0 {
+			statusLabel.SetText("Select a zone to build")
+			return
+		}
+		workers, err := parseCount(workersEntry.Text)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: workers %s", err))
+			return
+		}
+		maxBlender, err := parseCount(blenderEntry.Text)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: Blender instances %s", err))
+			return
+		}
+
+		c.mu.Lock()
+		if c.buildCancel != nil {
+			c.mu.Unlock()
+			statusLabel.SetText("Wait for the running build to finish")
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		c.buildCancel = cancel
+		c.cfg.BatchWorkers = workers
+		c.cfg.MaxBlender = maxBlender
+		err = c.cfg.Save()
+		c.mu.Unlock()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed saving config: %s", err))
+		}
+
+		rowMu.Lock()
+		rows = []*batchRow{}
+		for _, zone := range selected {
+			rows = append(rows, &batchRow{zone: zone, status: "queued"})
+		}
+		rowMu.Unlock()
+		table.Refresh()
+
+		buildButton.Disable()
+		closeButton.Disable()
+		stopButton.Enable()
+		c.convertButton.Disable()
+		statusLabel.SetText(fmt.Sprintf("Building %d zones", len(selected)))
+
+		isForce := forceCheck.Checked
+		batch := &build.Batch{
+			Workers:    workers,
+			MaxBlender: maxBlender,
+			New: func(zone string) *build.Builder {
+				c.mu.RLock()
+				b := build.New(c.cfg, c.currentPath, zone)
+				c.mu.RUnlock()
+				b.Version = resource.Version()
+				b.DefaultFiles = resource.ZoneFiles()
+				b.IsForceRebuild = isForce
+				b.OnEvent = func(e build.Event) {
+					if e.Type == build.EventStepStarted {
+						setRow(zone, fmt.Sprintf("%s (%d/%d)", e.Step, e.Index+1, e.Count), 0)
+					}
+				}
+				return b
+			},
+			OnStart: func(zone string) {
+				setRow(zone, "starting", 0)
+			},
+			OnResult: func(r *build.BatchResult) {
+				status := r.Status()
+				if r.Err != nil && !errors.Is(r.Err, context.Canceled) {
+					status = fmt.Sprintf("failed: %s", r.Err)
+				}
+				setRow(r.Zone, status, r.Elapsed)
+			},
+		}
+		go func() {
+			defer func() {
+				c.mu.Lock()
+				c.buildCancel = nil
+				c.mu.Unlock()
+				cancel()
+				buildButton.Enable()
+				closeButton.Enable()
+				stopButton.Disable()
+				c.convertButton.Enable()
+			}()
+			results := batch.Run(ctx, selected)
+			failed := []string{}
+			for _, r := range results {
+				if r.Err != nil {
+					failed = append(failed, r.Zone)
+				}
+				if r.Err != nil && errors.Is(r.Err, context.Canceled) {
+					setRow(r.Zone, r.Status(), r.Elapsed)
+				}
+			}
+			err := build.WriteSummaryLog(c.currentPath, resource.Version(), results)
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Failed %s", err))
+				return
+			}
+			text := fmt.Sprintf("Built %d of %d zones, summary in batch.log", len(results)-len(failed), len(results))
+			if len(failed) > 0 {
+				text += fmt.Sprintf(". Not built: %s", strings.Join(failed, ", "))
+			}
+			statusLabel.SetText(text)
+			c.logf("%s", text)
+		}()
+	})
+	stopButton = widget.NewButtonWithIcon("Stop", theme.MediaStopIcon(), func() {
+		stopButton.Disable()
+		c.mu.RLock()
+		cancel := c.buildCancel
+		c.mu.RUnlock()
+		if cancel != nil {
+			statusLabel.SetText("Stopping batch build")
+			cancel()
+		}
+	})
+	stopButton.Disable()
+	closeButton = widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+
+	popup = widget.NewModalPopUp(
+		container.NewBorder(
+			container.NewVBox(
+				widget.NewLabel("Batch build"),
+				container.NewHBox(widget.NewLabel("Zones:"), allButton, noneButton),
+				zoneScroll,
+				container.New(
+					layout.NewFormLayout(),
+					widget.NewLabel("Zones at once:"),
+					workersEntry,
+					widget.NewLabel("Blender instances:"),
+					blenderEntry,
+				),
+				forceCheck,
+				container.NewHBox(buildButton, stopButton, closeButton),
+				statusLabel,
+			),
+			nil, nil, nil,
+			table,
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(600, 560))
+	popup.Show()
+}
+
+// parseCount parses a count entry, empty is 0
+func parseCount(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	count, err := strconv.Atoi(text)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("%s is not a number", text)
+	}
+	return count, nil
+}
@@ -3,6 +3,85 @@
 
 package client
 
+import (
+	"context"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/blender"
+	"github.com/xackery/eqgzi-manager/build"
+)
+
 func (c *Client) onBlenderDetectButton() {
+	// before the window is built (first start), pick the newest install instead of asking
+	isStartup := c.canvas == nil
+	c.blenderDetectButton.Disable()
+	c.logf("Detecting blender...")
+	go func() {
+		defer c.blenderDetectButton.Enable()
+		installs := blender.Detect(context.Background())
+		if len(installs) == 0 {
+			c.logf("Failed to detect blender, set the path to the blender executable manually")
+			return
+		}
+		c.mu.RLock()
+		r := build.New(c.cfg, c.currentPath, c.cfg.LastZone).BlenderRange()
+		c.mu.RUnlock()
+		// prefer the newest install eqgzi supports
+		best := installs[0]
+		for _, install := range installs {
+			if r.Contains(install.Version) {
+				best = install
+				break
+			}
+		}
+		if len(installs) == 1 || isStartup {
+			c.setBlenderPath(best)
+			if len(installs) > 1 {
+				c.logf("Found %d blender installs, using %s. Press Detect Blender Path to choose another", len(installs), best.Version)
+			}
+			return
+		}
+
+		options := []string{}
+		selected := ""
+		for _, install := range installs {
+			option := install.String()
+			if !r.Contains(install.Version) {
+				option += " unsupported"
+			}
+			if install == best {
+				selected = option
+			}
+			options = append(options, option)
+		}
+		radio := widget.NewRadioGroup(options, nil)
+		radio.SetSelected(selected)
+		dialog.ShowCustomConfirm("Choose Blender", "Use", "Cancel", radio, func(isOk bool) {
+			if !isOk {
+				c.logf("Cancelled blender detect")
+				return
+			}
+			for i, option := range options {
+				if option == radio.Selected {
+					c.setBlenderPath(installs[i])
+					return
+				}
+			}
+		}, c.window)
+	}()
+}
 
+// setBlenderPath saves install as the blender to build and open zones with
+func (c *Client) setBlenderPath(install *blender.Install) {
+	c.blenderPathInput.SetText(install.Path)
+	c.mu.Lock()
+	c.cfg.BlenderPath = install.Path
+	err := c.cfg.Save()
+	c.mu.Unlock()
+	if err != nil {
+		c.logf("Failed save: %s", err)
+		return
+	}
+	c.logf("Updated blender path to %s", install)
 }
@@ -27,8 +27,10 @@ func (c *Client) onBlenderDetectButton() {
 	s = strings.TrimSuffix(s, "blender-launcher.exe")
 	//s += "blender.exe"
 	c.blenderPathInput.SetText(s)
+	c.mu.Lock()
 	c.cfg.BlenderPath = s
 	err = c.cfg.Save()
+	c.mu.Unlock()
 	if err != nil {
 		c.logf("Failed save: %s", err)
 		return
@@ -7,7 +7,8 @@ import (
 	"os/exec"
 )
 
+// createCommand returns a command running name with arg, through Wine for a windows program when wine_path is set
 func (c *Client) createCommand(isHidden bool, name string, arg ...string) *exec.Cmd {
-	cmd := exec.Command(name, arg...)
+	cmd := c.launcher().Command(name, arg...)
 	return cmd
 }
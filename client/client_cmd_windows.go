@@ -8,8 +8,9 @@ import (
 	"syscall"
 )
 
+// createCommand returns a command running name with arg
 func (c *Client) createCommand(isHidden bool, name string, arg ...string) *exec.Cmd {
-	cmd := exec.Command(name, arg...)
+	cmd := c.launcher().Command(name, arg...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: isHidden}
 	return cmd
 }
@@ -1,229 +1,130 @@
 package client
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"os"
-	"strconv"
 	"strings"
+	"time"
+
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/resource"
 )
 
 func (c *Client) onConvertButton() {
 	c.mu.RLock()
-	currentPath := c.currentPath
-	zone := c.cfg.LastZone
-	isEQCopy := c.cfg.IsEQCopy
-	eqPath := c.cfg.EQPath
-	isServerCopy := c.cfg.IsServerCopy
-	serverPath := c.cfg.ServerPath
-	blenderPath := c.cfg.BlenderPath
+	b := build.New(c.cfg, c.currentPath, c.cfg.LastZone)
 	c.mu.RUnlock()
-	c.logf("Converting %s", zone)
-
-	c.progressBar.Show()
-	c.progress = 0
-	c.progressBar.SetValue(c.addProgress(0.1))
-	c.statusLabel.Hide()
-	defer func() {
-		c.progressBar.Hide()
-		c.statusLabel.Show()
-	}()
-
-	env := []string{
-		fmt.Sprintf(`PATH=%s;%s\tools`, blenderPath, currentPath),
-		fmt.Sprintf(`EQPATH=%s`, strings.ReplaceAll(eqPath, "/", `\`)),
-		fmt.Sprintf(`EQGZI=%s\tools\`, currentPath),
-		fmt.Sprintf(`ZONE=%s`, zone),
-		fmt.Sprintf(`EQSERVERPATH=%s`, strings.ReplaceAll(serverPath, "/", `\`)),
-		fmt.Sprintf(`BLENDERPATH=%s`, blenderPath),
-	}
-
-	cmd := c.createCommand(true, fmt.Sprintf("%s/zones/%s/convert.bat", currentPath, zone))
-	cmd.Dir = fmt.Sprintf("%s/zones/%s/", currentPath, zone)
-	cmd.Env = env
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		c.logf("Failed to start convert.bat: stdoutpipe: %s", err)
-		return
-	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		c.logf("Failed to start convert.bat: stderrpipe: %s", err)
-		return
-	}
-	err = cmd.Start()
-	if err != nil {
-		c.logf("Failed to run convert.bat: %s", err)
-		return
-	}
-
-	reader := io.MultiReader(stdout, stderr)
-	c.progressBar.SetValue(c.addProgress(0.1))
-	err = c.processOutput(reader, currentPath, zone, "convert.log")
-	if err != nil {
-		c.logf("Failed stdout: %s", err)
-		return
-	}
-	err = cmd.Wait()
-	if err != nil {
-		c.logf("Failed convert.bat: %s", err)
-		return
-	}
-
-	if isEQCopy {
-		cmd := c.createCommand(true, fmt.Sprintf("%s/zones/%s/copy_eq.bat", currentPath, zone))
-		cmd.Dir = fmt.Sprintf("%s/zones/%s/", currentPath, zone)
-		cmd.Env = env
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			c.logf("Failed to start copy_eq.bat: stdoutpipe: %s", err)
-			return
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			c.logf("Failed to start copy_eq.bat: stderrpipe: %s", err)
-			return
-		}
-		reader = io.MultiReader(stdout, stderr)
-		err = cmd.Start()
-		if err != nil {
-			c.logf("Failed to run copy_eq.bat: %s", err)
-			return
-		}
-		err = c.processOutput(reader, currentPath, zone, "copy_eq.log")
-		if err != nil {
-			c.logf("Failed copy_eq stdout: %s", err)
+	b.IsForceRebuild = c.forceRebuildCheck.Checked
+	c.runBuilder(b, "build", b.Run, func() {
+		if len(b.Reused) > 0 {
+			c.logf("Created %s.eqg, reused unchanged %s", b.Zone, strings.Join(b.Reused, ", "))
 			return
 		}
-		err = cmd.Wait()
-		if err != nil {
-			c.logf("Failed copy_eq.bat: %s", err)
-			return
-		}
-		c.progressBar.SetValue(c.addProgress(0.1))
-
-	}
-
-	if isServerCopy {
-		cmd = c.createCommand(true, fmt.Sprintf("%s/zones/%s/copy_server.bat", currentPath, zone))
-		cmd.Dir = fmt.Sprintf("%s/zones/%s/", currentPath, zone)
-		cmd.Env = env
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			c.logf("Failed to start copy_server.bat: stdoutpipe: %s", err)
-			return
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			c.logf("Failed to start copy_server.bat: stderrpipe: %s", err)
-			return
-		}
-		err = cmd.Start()
-		if err != nil {
-			c.logf("Failed to run copy_server.bat: %s", err)
-			return
-		}
-		reader = io.MultiReader(stdout, stderr)
-		err = c.processOutput(reader, currentPath, zone, "copy_server.log")
-		if err != nil {
-			c.logf("Failed copy_server stdout: %s", err)
-			return
-		}
-		err = cmd.Wait()
-		if err != nil {
-			c.logf("Failed copy_server.bat: %s", err)
-			return
-		}
-		c.progressBar.SetValue(c.addProgress(0.1))
-
-	}
-	c.logf("Created %s.eqg", zone)
+		c.logf("Created %s.eqg", b.Zone)
+	})
 }
 
-func (c *Client) processOutput(in io.Reader, currentPath string, zone string, logName string) error {
-	buf := bufio.NewReader(in)
-	lineNumber := 0
-	outLog, err := os.Create(fmt.Sprintf("%s/zones/%s/%s", currentPath, zone, logName))
-	if err != nil {
-		return fmt.Errorf("create %s: %s", logName, err)
+// runBuilder runs fn, showing the progress of b in the main window until it ends or Stop is pressed.
+// onSuccess is called if fn succeeds. The returned channel receives the result of fn,
+// it is nil if another build is running
+func (c *Client) runBuilder(b *build.Builder, name string, fn func(ctx context.Context) error, onSuccess func()) <-chan error {
+	c.mu.Lock()
+	if c.buildCancel != nil {
+		c.mu.Unlock()
+		c.logf("Wait for the running build to finish")
+		return nil
 	}
-	defer outLog.Close()
-	_, err = outLog.WriteString(fmt.Sprintf("Initialized from eqgzi-manager v%s", VersionText.Content()))
-	if err != nil {
-		return fmt.Errorf("write to %s: %s", logName, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.buildCancel = cancel
+	c.mu.Unlock()
+	b.Version = resource.Version()
+	b.DefaultFiles = resource.ZoneFiles()
+	b.Logf = c.logf
+
+	stepName := ""
+	stepText := ""
+	stepStart := time.Now()
+	b.OnEvent = func(e build.Event) {
+		c.mu.Lock()
+		if e.Type == build.EventStepStarted {
+			stepName = e.Step
+			stepText = fmt.Sprintf("%d/%d", e.Index+1, e.Count)
+			stepStart = time.Now()
+		}
+		c.mu.Unlock()
+		c.progressBar.SetValue(e.Overall())
 	}
-	failedMessage := ""
-	isMainCommandError := false
-
-	step := 0
-
-	for {
-		lineNumber++
-		line, err := buf.ReadString('\n')
-		if err == io.EOF {
-			break
+	c.progressBar.TextFormatter = func() string {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		if stepName == "" {
+			return "Starting"
 		}
-		if err != nil {
-			return fmt.Errorf("read %s: %s", logName, err)
-		}
-		fmt.Printf("%s:%d %s", logName, lineNumber, line)
+		return fmt.Sprintf("Step %s %s (%s)", stepText, stepName, time.Since(stepStart).Round(time.Second))
+	}
 
-		if logName == "convert.log" && strings.HasPrefix(line, "Step") && len(line) > 7 {
-			stepNum, err := strconv.Atoi(line[5:6])
-			if err != nil { //can be ignored
-				c.logf("minor note %s:%d %s %s", logName, lineNumber, line, err)
-			} else {
-				step = stepNum
-				c.progressBar.SetValue(c.addProgress(0.05))
-			}
-		}
+	c.convertButton.Disable()
+	c.diagnosticsButton.Hide()
+	c.stopButton.Show()
+	c.progressBar.SetValue(0)
+	c.progressBar.Show()
+	c.statusLabel.Hide()
 
-		//Keyerrors are great source errors
-		if strings.HasPrefix(line, "KeyError:") {
-			context := ""
-			if strings.Contains(line, "not found") && strings.Contains(line, "bpy_prop_collection") {
-				context = " (an image texture is not properly exported)"
+	// refresh the elapsed time of the running step
+	ticker := time.NewTicker(time.Second)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.progressBar.Refresh()
 			}
-			failedMessage = fmt.Sprintf("%s:%d %s%s", logName, lineNumber, line, context)
-		}
-
-		if failedMessage == "" && strings.Contains(line, "GPUTexture: Blender Texture Not Loaded!") {
-			failedMessage = fmt.Sprintf("%s:%d %s (a reference to a texture in blender is broken)", logName, lineNumber, line)
-		}
-
-		if failedMessage == "" && strings.Contains(line, "failed to find") && strings.Contains(line, "in current path, defined") {
-			failedMessage = fmt.Sprintf("%s:%d %s (texture missing)", logName, lineNumber, line)
 		}
+	}()
 
-		if failedMessage == "" && isMainCommandError {
-			failedMessage = fmt.Sprintf("%s:%d %s", logName, lineNumber, line)
-			isMainCommandError = false
-		}
-		if failedMessage == "" && strings.Contains(line, "missing") && strings.Contains(line, "not copying") {
-			failedMessage = fmt.Sprintf("%s:%d %s", logName, lineNumber, line)
-		}
-		if failedMessage == "" && strings.Contains(line, "error") && !strings.Contains(line, "main_cmd error") {
-			failedMessage = fmt.Sprintf("%s:%d %s", logName, lineNumber, line)
-		}
-		if step >= 7 && failedMessage == "" && strings.Contains(line, "main_cmd error:") {
-			isMainCommandError = true
-		}
-		if failedMessage == "" && strings.Contains(line, "PermissionError: [Errno 13] Permission denied: '.'") {
-			failedMessage = fmt.Sprintf("%s:%d %s (This is usually caused by an embedded image)", logName, lineNumber, line)
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			ticker.Stop()
+			c.mu.Lock()
+			c.buildCancel = nil
+			c.mu.Unlock()
+			cancel()
+			c.progressBar.Hide()
+			c.progressBar.TextFormatter = nil
+			c.stopButton.Hide()
+			c.stopButton.Enable()
+			c.convertButton.Enable()
+			c.statusLabel.Show()
+			c.setDiagnostics(b.Diagnostics)
+			done <- err
+		}()
+
+		err = fn(ctx)
+		if errors.Is(err, context.Canceled) {
+			c.logf("Cancelled %s %s", b.Zone, name)
+			return
 		}
-		_, err = outLog.WriteString(line)
 		if err != nil {
-			return fmt.Errorf("write string to %s: %s", logName, err)
+			c.logf("Failed %s", err)
+			return
 		}
-	}
+		onSuccess()
+	}()
+	return done
+}
 
-	if failedMessage != "" {
-		return fmt.Errorf(failedMessage)
-	}
-	if logName == "convert.log" && step < 7 {
-		return fmt.Errorf("convert.bat failed at step %d", step)
+func (c *Client) onStopButton() {
+	c.mu.RLock()
+	cancel := c.buildCancel
+	c.mu.RUnlock()
+	if cancel == nil {
+		return
 	}
-	return nil
+	c.stopButton.Disable()
+	c.logf("Stopping build")
+	cancel()
 }
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/build"
+)
+
+// setDiagnostics updates the diagnostics button with the problems of the last build
+func (c *Client) setDiagnostics(diagnostics []*build.Diagnostic) {
+	c.mu.Lock()
+	c.diagnostics = diagnostics
+	c.mu.Unlock()
+	if len(diagnostics) == 0 {
+		c.diagnosticsButton.Hide()
+		return
+	}
+
+	errorCount := 0
+	for _, d := range diagnostics {
+		if d.Rule.Severity == build.SeverityError {
+			errorCount++
+		}
+	}
+	c.diagnosticsButton.SetText(fmt.Sprintf("Build problems: %d errors, %d warnings", errorCount, len(diagnostics)-errorCount))
+	c.diagnosticsButton.Show()
+}
+
+func (c *Client) onDiagnosticsButton() {
+	c.mu.RLock()
+	diagnostics := c.diagnostics
+	zone := c.cfg.LastZone
+	c.mu.RUnlock()
+
+	list := widget.NewList(
+		func() int { return len(diagnostics) },
+		func() fyne.CanvasObject {
+			// three lines: location, explanation and fix
+			detail := widget.NewLabel("\n\n")
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.WarningIcon()), nil, detail)
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			d := diagnostics[id]
+			box := o.(*fyne.Container)
+			detail := box.Objects[0].(*widget.Label)
+			icon := box.Objects[1].(*widget.Icon)
+			if d.Rule.Severity == build.SeverityError {
+				icon.SetResource(theme.ErrorIcon())
+			} else {
+				icon.SetResource(theme.WarningIcon())
+			}
+			lines := []string{fmt.Sprintf("%s:%d [%s] %s", d.Log, d.Line, d.Step, strings.TrimSpace(d.Text))}
+			if d.Rule.Explanation != "" {
+				lines = append(lines, d.Rule.Explanation)
+			}
+			if d.Rule.Fix != "" {
+				lines = append(lines, "Fix: "+d.Rule.Fix)
+			}
+			detail.SetText(strings.Join(lines, "\n"))
+		},
+	)
+	var popup *widget.PopUp
+	closeButton := widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+	popup = widget.NewModalPopUp(
+		container.NewBorder(
+			widget.NewLabel(fmt.Sprintf("Build problems of %s", zone)),
+			closeButton,
+			nil,
+			nil,
+			list,
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(560, 420))
+	popup.Show()
+}
@@ -1,25 +1,18 @@
 package client
 
 import (
-	"archive/zip"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/xackery/eqgzi-manager/release"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/selfupdate"
+	"github.com/xackery/eqgzi-manager/tool"
 )
 
-type gitReply struct {
-	TagName string `json:"tag_name"`
-	Assets  []struct {
-		Name               string `json:"name"`
-		Size               int    `json:"size"`
-		BrowserDownloadURL string `json:"browser_download_url"`
-	} `json:"assets"`
-}
-
 func (c *Client) onDownloadEQGZIButton() {
 
 	c.downloadEQGZIButton.Disable()
@@ -45,256 +38,119 @@ func (c *Client) onDownloadEQGZIButton() {
 }
 
 func (c *Client) downloadEQGZI() error {
+	c.mu.RLock()
+	pin := c.cfg.EQGZIPin
+	c.mu.RUnlock()
 
-	c.progressBar.SetValue(c.addProgress(0.1))
-
-	gitReply := &gitReply{}
-	req, err := http.NewRequest("GET", "https://api.github.com/repos/xackery/eqgzi/releases/latest", nil)
+	m, err := c.toolManager()
 	if err != nil {
-		return fmt.Errorf("new git request: %w", err)
+		return err
 	}
-
 	c.progressBar.SetValue(c.addProgress(0.1))
-
-	client := http.DefaultClient
-	resp, err := client.Do(req)
+	c.logf("Downloading eqgzi %s", pin)
+	eqgziVersion, err := m.Download(context.Background(), tool.EQGZI, pin)
 	if err != nil {
-		return fmt.Errorf("do git request: %w", err)
-	}
-
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(gitReply)
-	resp.Body.Close()
-	if err != nil {
-		return fmt.Errorf("decode git request: %w", err)
-	}
-	assetURL := ""
-	c.progressBar.SetValue(c.addProgress(0.05))
-
-	zipName := fmt.Sprintf("eqgzi-%s.zip", gitReply.TagName)
-	for _, asset := range gitReply.Assets {
-		if asset.Name != zipName {
-			continue
-		}
-		assetURL = asset.BrowserDownloadURL
-	}
-	if assetURL == "" {
-		return fmt.Errorf("download eqgzi zip not found")
-	}
-	c.logf("downloading %s", assetURL)
-	c.progressBar.SetValue(c.addProgress(0.05))
-
-	err = os.Mkdir("cache", os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return fmt.Errorf("mkdir cache: %w", err)
-	}
-
-	_, err = os.Stat(fmt.Sprintf("cache/%s", zipName))
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("stat cache/%s: %w", zipName, err)
-		}
-		req, err = http.NewRequest("GET", assetURL, nil)
-		if err != nil {
-			return fmt.Errorf("asset req: %w", err)
-		}
-
-		resp, err = client.Do(req)
-		if err != nil {
-			return fmt.Errorf("asset do: %w", err)
-		}
-
-		w, err := os.Create(fmt.Sprintf("cache/%s", zipName))
-		if err != nil {
-			return fmt.Errorf("create zip: %w", err)
-		}
-		defer w.Close()
-
-		_, err = io.Copy(w, resp.Body)
-		if err != nil {
-			return fmt.Errorf("copy zip: %w", err)
-		}
+		return err
 	}
+	c.progressBar.SetValue(c.addProgress(0.4))
 
-	c.progressBar.SetValue(c.addProgress(0.05))
-
-	c.logf("Extracting %s", zipName)
-	err = os.Mkdir("tools", os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return fmt.Errorf("mkdir tools: %w", err)
-	}
-	zr, err := zip.OpenReader(fmt.Sprintf("cache/%s", zipName))
+	c.logf("Downloading LanternExtractor")
+	lanternVersion, err := m.Download(context.Background(), tool.Lantern, "")
 	if err != nil {
-		return fmt.Errorf("open zip: %w", err)
+		return fmt.Errorf("lantern: %w", err)
 	}
-	defer zr.Close()
+	c.progressBar.SetValue(c.addProgress(0.4))
 
-	err = os.MkdirAll("tools/ClientData", os.ModePerm)
+	c.mu.Lock()
+	c.cfg.EQGZIVersion = eqgziVersion
+	c.cfg.LanternVersion = lanternVersion
+	err = c.cfg.Save()
+	c.mu.Unlock()
 	if err != nil {
-		return fmt.Errorf("mkdir tools/ClientData: %w", err)
+		return fmt.Errorf("save config: %w", err)
 	}
+	c.logf("Installed eqgzi %s and LanternExtractor %s", eqgziVersion, lanternVersion)
+	return nil
+}
 
-	for _, zf := range zr.File {
-		filePath := fmt.Sprintf("tools/%s", zf.Name)
-
-		if zf.FileInfo().IsDir() {
-			err = os.MkdirAll(filePath, os.ModePerm)
-			if err != nil {
-				return fmt.Errorf("mkdir %s: %w", filePath, err)
-			}
-			continue
-		}
-
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, zf.Mode())
+func (c *Client) onDownloadButton() {
+	c.downloadButton.Disable()
+	go func() {
+		defer c.downloadButton.Enable()
+		u, err := c.newUpdater()
 		if err != nil {
-			return fmt.Errorf("open fs %s: %w", filePath, err)
+			c.logf("Failed update check: %s", err)
+			return
 		}
-
-		fileInArchive, err := zf.Open()
+		c.logf("Checking for eqgzi-manager updates")
+		up, err := u.Check(context.Background())
 		if err != nil {
-			return fmt.Errorf("open zip %s: %w", zf.Name, err)
+			c.logf("Failed update check: %s", err)
+			return
 		}
-
-		_, err = io.Copy(dstFile, fileInArchive)
-		if err != nil {
-			return fmt.Errorf("copy %s: %w", zf.Name, err)
+		if up == nil {
+			c.logf("eqgzi-manager v%s is up to date", u.Current)
+			return
 		}
-
-		c.progressBar.SetValue(c.addProgress(0.01))
-
-		dstFile.Close()
-		fileInArchive.Close()
-	}
-
-	c.progressBar.SetValue(c.addProgress(0.01))
-
-	c.cfg.EQGZIVersion = gitReply.TagName
-	c.cfg.Save()
-	return nil
+		dialog.ShowConfirm("Download Update", fmt.Sprintf("Update eqgzi-manager from v%s to v%s?", u.Current, up.Version), func(isOk bool) {
+			if !isOk {
+				return
+			}
+			go c.installUpdate(u, up)
+		}, c.window)
+	}()
 }
-/*
-func (c *Client) onDownloadLantern() error {
-	c.progressBar.SetValue(c.addProgress(0.1))
 
-	gitReply := &gitReply{}
-	req, err := http.NewRequest("GET", "https://api.github.com/repos/LanternEQ/LanternExtractor/releases/latest", nil)
+func (c *Client) installUpdate(u *selfupdate.Updater, up *selfupdate.Update) {
+	c.downloadButton.Disable()
+	defer c.downloadButton.Enable()
+	c.logf("Downloading eqgzi-manager v%s", up.Version)
+	path, err := u.Install(context.Background(), up)
 	if err != nil {
-		return fmt.Errorf("new git request: %w", err)
-	}
-
-	c.progressBar.SetValue(c.addProgress(0.05))
-
-	client := http.DefaultClient
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("do git request: %w", err)
-	}
-
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(gitReply)
-	resp.Body.Close()
-	if err != nil {
-		return fmt.Errorf("decode git request: %w", err)
-	}
-	assetURL := ""
-	c.progressBar.SetValue(c.addProgress(0.05))
-
-	zipName := fmt.Sprintf("LanternExtractor-%s.zip", gitReply.TagName)
-	for _, asset := range gitReply.Assets {
-		if asset.Name != zipName {
-			continue
-		}
-		assetURL = asset.BrowserDownloadURL
-	}
-	if assetURL == "" {
-		return fmt.Errorf("download eqgzi zip not found")
-	}
-	c.logf("downloading %s", assetURL)
-	c.progressBar.SetValue(c.addProgress(0.05))
-
-	err = os.Mkdir("cache", os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return fmt.Errorf("mkdir cache: %w", err)
+		c.logf("Failed update: %s", err)
+		return
 	}
-
-	_, err = os.Stat(fmt.Sprintf("cache/%s", zipName))
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("stat cache/%s: %w", zipName, err)
-		}
-		req, err = http.NewRequest("GET", assetURL, nil)
-		if err != nil {
-			return fmt.Errorf("asset req: %w", err)
-		}
-
-		resp, err = client.Do(req)
-		if err != nil {
-			return fmt.Errorf("asset do: %w", err)
-		}
-
-		w, err := os.Create(fmt.Sprintf("cache/%s", zipName))
-		if err != nil {
-			return fmt.Errorf("create zip: %w", err)
+	c.logf("Updated to v%s, restart to use it", up.Version)
+	dialog.ShowConfirm("Restart", fmt.Sprintf("Updated to v%s. Restart eqgzi-manager now?", up.Version), func(isOk bool) {
+		if !isOk {
+			return
 		}
-		defer w.Close()
-
-		_, err = io.Copy(w, resp.Body)
+		err := selfupdate.Restart(path, os.Args[1:])
 		if err != nil {
-			return fmt.Errorf("copy zip: %w", err)
+			c.logf("Failed restart: %s", err)
+			return
 		}
-	}
-
-	c.progressBar.SetValue(c.addProgress(0.05))
+		os.Exit(0)
+	}, c.window)
+}
 
-	c.logf("Extracting %s", zipName)
-	err = os.Mkdir("tools", os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return fmt.Errorf("mkdir tools: %w", err)
-	}
-	zr, err := zip.OpenReader(fmt.Sprintf("cache/%s", zipName))
+func (c *Client) newUpdater() (*selfupdate.Updater, error) {
+	src, err := c.releaseSource()
 	if err != nil {
-		return fmt.Errorf("open zip: %w", err)
+		return nil, err
 	}
-	defer zr.Close()
-
-	for _, zf := range zr.File {
-		filePath := fmt.Sprintf("tools/%s", zf.Name)
-
-		if zf.FileInfo().IsDir() {
-			err = os.MkdirAll(filePath, os.ModePerm)
-			if err != nil {
-				return fmt.Errorf("mkdir %s: %w", filePath, err)
-			}
-			continue
-		}
-
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, zf.Mode())
-		if err != nil {
-			return fmt.Errorf("open fs %s: %w", filePath, err)
-		}
-
-		fileInArchive, err := zf.Open()
-		if err != nil {
-			return fmt.Errorf("open zip %s: %w", zf.Name, err)
-		}
-
-		_, err = io.Copy(dstFile, fileInArchive)
-		if err != nil {
-			return fmt.Errorf("copy %s: %w", zf.Name, err)
-		}
-
-		c.progressBar.SetValue(c.addProgress(0.05))
+	return &selfupdate.Updater{
+		Current: resource.Version(),
+		Source:  src,
+	}, nil
+}
 
-		dstFile.Close()
-		fileInArchive.Close()
+// toolManager returns a tool manager downloading from the configured release source
+func (c *Client) toolManager() (*tool.Manager, error) {
+	src, err := c.releaseSource()
+	if err != nil {
+		return nil, err
 	}
+	c.mu.RLock()
+	m := tool.New(c.currentPath)
+	c.mu.RUnlock()
+	m.Source = src
+	return m, nil
+}
 
-	c.progressBar.SetValue(c.addProgress(0.05))
-	c.cfg.LanternVersion = gitReply.TagName
-	c.cfg.Save()
-	return nil
-} */
-
-func (c *Client) onDownloadButton() {
-	c.logf("TODO: Implement Download Update")
+// releaseSource returns where tools and updates are downloaded from
+func (c *Client) releaseSource() (release.Source, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return release.New(c.cfg.ReleaseSource, c.cfg.ReleaseURL)
 }
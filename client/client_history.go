@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/build"
+)
+
+const historyPreviousOption = "Build before it"
+
+func (c *Client) onHistoryButton() {
+	c.mu.RLock()
+	zone := c.cfg.LastZone
+	currentPath := c.currentPath
+	c.mu.RUnlock()
+
+	runs, err := build.Runs(currentPath, zone)
+	if err != nil {
+		c.logf("Failed history: %s", err)
+		return
+	}
+	if len(runs) == 0 {
+		c.logf("%s has no recorded builds yet", zone)
+		return
+	}
+
+	detailLabel := widget.NewLabel("")
+	detailLabel.Wrapping = fyne.TextWrapWord
+	compareOptions := []string{historyPreviousOption}
+	for _, run := range runs {
+		compareOptions = append(compareOptions, run.ID)
+	}
+	selected := 0
+	compareSelect := widget.NewSelect(compareOptions, nil)
+	compareSelect.SetSelected(historyPreviousOption)
+	showRun := func() {
+		run := runs[selected]
+		var previous *build.Run
+		if compareSelect.Selected == historyPreviousOption {
+			previous = build.PreviousRun(runs, selected)
+		} else {
+			for _, other := range runs {
+				if other.ID == compareSelect.Selected {
+					previous = other
+				}
+			}
+		}
+		detailLabel.SetText(run.Details(previous) + fmt.Sprintf("\n\nLogs: %s", run.Dir()))
+	}
+	compareSelect.OnChanged = func(string) { showRun() }
+
+	list := widget.NewList(func() int {
+		return len(runs)
+	}, func() fyne.CanvasObject {
+		return container.NewHBox(widget.NewIcon(theme.ConfirmIcon()), widget.NewLabel(""))
+	}, func(id widget.ListItemID, o fyne.CanvasObject) {
+		run := runs[id]
+		row := o.(*fyne.Container)
+		icon := row.Objects[0].(*widget.Icon)
+		switch run.Result {
+		case "ok":
+			icon.SetResource(theme.ConfirmIcon())
+		case "cancelled":
+			icon.SetResource(theme.WarningIcon())
+		default:
+			icon.SetResource(theme.ErrorIcon())
+		}
+		text := fmt.Sprintf("%s  %s  %.1f KB", run.Time.Format("2006-01-02 15:04:05"), run.Duration.Round(100*time.Millisecond), float64(run.Size())/1024)
+		previous := build.PreviousRun(runs, id)
+		if previous != nil && run.IsBuilt() {
+			text += fmt.Sprintf("  (%s)", build.SizeDelta(previous.Size(), run.Size()))
+		}
+		row.Objects[1].(*widget.Label).SetText(text)
+	})
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		showRun()
+	}
+
+	var popup *widget.PopUp
+	closeButton := widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+	popup = widget.NewModalPopUp(
+		container.NewBorder(
+			widget.NewLabel(fmt.Sprintf("%s build history, newest first", zone)),
+			container.NewHBox(widget.NewLabel("Compare with:"), compareSelect, closeButton),
+			nil, nil,
+			container.NewHSplit(list, container.NewVScroll(detailLabel)),
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(860, 520))
+	popup.Show()
+	list.Select(0)
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+func (c *Client) onImportButton() {
+	c.mu.RLock()
+	eqPath := c.cfg.EQPath
+	c.mu.RUnlock()
+	if eqPath == "" {
+		c.logf("Set the EverQuest path before importing a zone")
+		return
+	}
+
+	eqZones, err := build.EQZones(eqPath)
+	if err != nil {
+		c.logf("Failed to list EverQuest zones: %s", err)
+		return
+	}
+	if len(eqZones) == 0 {
+		c.logf("No zone .s3d files found in %s", eqPath)
+		return
+	}
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapBreak
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("same as EverQuest zone")
+	eqZoneSelect := widget.NewSelectEntry(eqZones)
+	eqZoneSelect.SetPlaceHolder("short name, e.g. gfaydark")
+	nameHint := func(string) {
+		name := nameEntry.Text
+		if strings.TrimSpace(name) == "" {
+			name = eqZoneSelect.Text
+		}
+		statusLabel.SetText(zoneNameHint(name))
+	}
+	nameEntry.OnChanged = nameHint
+	eqZoneSelect.OnChanged = nameHint
+	blendCheck := widget.NewCheck("Import into .blend with Blender", nil)
+
+	var popup *widget.PopUp
+	importButton := widget.NewButtonWithIcon("Import", theme.DownloadIcon(), func() {
+		eqZone := strings.ToLower(strings.TrimSpace(eqZoneSelect.Text))
+		if eqZone == "" {
+			statusLabel.SetText("Failed: EverQuest zone cannot be empty")
+			return
+		}
+		name := strings.ToLower(strings.TrimSpace(nameEntry.Text))
+		if name == "" {
+			name = eqZone
+		}
+		warnings, err := c.checkZoneName(name)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: %s", err))
+			return
+		}
+		c.mu.RLock()
+		currentPath := c.currentPath
+		isBuilding := c.buildCancel != nil
+		c.mu.RUnlock()
+		if isBuilding {
+			statusLabel.SetText("Failed: wait for the running build to finish")
+			return
+		}
+		err = zone.Create(currentPath, name, resource.ZoneFiles())
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: %s", err))
+			return
+		}
+		popup.Hide()
+		c.onZoneRefresh()
+		c.zoneCombo.SetSelected(name)
+
+		c.mu.RLock()
+		b := build.New(c.cfg, currentPath, name)
+		c.mu.RUnlock()
+		isBlend := blendCheck.Checked
+		done := c.runBuilder(b, "import", func(ctx context.Context) error {
+			return b.Import(ctx, eqZone, isBlend)
+		}, func() {
+			c.logWarnings(warnings, "Created zones/%s from %s", name, eqZone)
+		})
+		go func() {
+			if done != nil && <-done == nil {
+				return
+			}
+			c.discardImport(name)
+		}()
+	})
+	cancelButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+
+	popup = widget.NewModalPopUp(
+		container.NewVBox(
+			widget.NewLabel("Import a zone from EverQuest with LanternExtractor"),
+			container.New(
+				layout.NewFormLayout(),
+				widget.NewLabel("EverQuest zone:"),
+				eqZoneSelect,
+				widget.NewLabel("New zone name:"),
+				nameEntry,
+			),
+			blendCheck,
+			container.NewHBox(importButton, cancelButton),
+			statusLabel,
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(420, 0))
+	popup.Show()
+	c.window.Canvas().Focus(eqZoneSelect)
+}
+
+// discardImport moves a zone whose import failed, was cancelled or never started to the trash,
+// so the name can be imported again. Any import.log is kept there
+func (c *Client) discardImport(name string) {
+	c.mu.Lock()
+	trashName, err := zone.Delete(c.currentPath, name)
+	if err == nil {
+		c.cfg.RemoveZone(name)
+		err = c.cfg.Save()
+	}
+	c.mu.Unlock()
+	if err != nil {
+		c.logf("Failed to remove unfinished zones/%s: %s", name, err)
+		return
+	}
+	c.selectFirstZone()
+	c.logf("Moved unfinished zones/%s to trash/%s", name, trashName)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/pfs"
+)
+
+func (c *Client) onInspectButton() {
+	c.mu.RLock()
+	currentPath := c.currentPath
+	zone := c.cfg.LastZone
+	c.mu.RUnlock()
+
+	a, err := pfs.Open(fmt.Sprintf("%s/zones/%s/out/%s.eqg", currentPath, zone, zone))
+	if err != nil {
+		c.logf("Failed to open %s.eqg: %s", zone, err)
+		return
+	}
+	defer a.Close()
+
+	summary := fmt.Sprintf("%d files, all references packed", len(a.Entries))
+	missing, err := a.MissingReferences()
+	if err != nil {
+		summary = fmt.Sprintf("Failed references: %s", err)
+	} else if len(missing) > 0 {
+		summary = fmt.Sprintf("Missing: %s", strings.Join(missing, ", "))
+	}
+
+	rows := [][]string{{"Name", "Size", "Compressed", "CRC"}}
+	for _, e := range a.Entries {
+		rows = append(rows, []string{e.Name, fmt.Sprintf("%d", e.Size), fmt.Sprintf("%d", e.CompressedSize), fmt.Sprintf("%08x", e.CRC)})
+	}
+
+	table := widget.NewTable(
+		func() (int, int) { return len(rows), len(rows[0]) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(rows[id.Row][id.Col])
+		},
+	)
+	table.SetColumnWidth(0, 200)
+
+	summaryLabel := widget.NewLabel(summary)
+	summaryLabel.Wrapping = fyne.TextWrapBreak
+
+	var popup *widget.PopUp
+	closeButton := widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+	popup = widget.NewModalPopUp(
+		container.NewBorder(
+			widget.NewLabel(fmt.Sprintf("Contents of %s.eqg", zone)),
+			container.NewVBox(summaryLabel, closeButton),
+			nil,
+			nil,
+			table,
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(540, 400))
+	popup.Show()
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/tool"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+func (c *Client) onManifestButton() {
+	c.mu.RLock()
+	zoneName := c.cfg.LastZone
+	dir := filepath.Join(c.currentPath, "zones", zoneName)
+	b := build.New(c.cfg, c.currentPath, zoneName)
+	c.mu.RUnlock()
+	if zoneName == "" {
+		return
+	}
+
+	m, err := zone.LoadManifest(dir)
+	if err != nil {
+		c.logf("Failed %s", err)
+		return
+	}
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapBreak
+
+	longNameEntry := widget.NewEntry()
+	longNameEntry.SetText(m.LongName)
+	zoneIDEntry := widget.NewEntry()
+	if m.ZoneID > 0 {
+		zoneIDEntry.SetText(strconv.Itoa(m.ZoneID))
+	}
+	blenderEntry := widget.NewEntry()
+	blenderEntry.SetPlaceHolder("blender_path of eqgzi-manager.conf")
+	blenderEntry.SetText(m.BlenderPath)
+
+	versions, err := tool.New(c.currentPath).Versions(tool.EQGZI)
+	if err != nil {
+		statusLabel.SetText(fmt.Sprintf("Failed to list eqgzi versions: %s", err))
+	}
+	eqgziSelect := widget.NewSelect(append([]string{toolWorkspaceOption}, versions...), nil)
+	eqgziSelect.SetSelected(toolWorkspaceOption)
+	if m.EQGZIVersion != "" {
+		eqgziSelect.SetSelected(m.EQGZIVersion)
+	}
+
+	stepNames := []string{}
+	for _, step := range b.ConvertPipeline().Steps {
+		stepNames = append(stepNames, step.Name)
+	}
+	if len(m.ExtraOutputs) > 0 {
+		stepNames = append(stepNames, "extraoutputs")
+	}
+	for _, step := range m.Steps {
+		stepNames = append(stepNames, step.Name)
+	}
+	skipGroup := widget.NewCheckGroup(stepNames, nil)
+	skipGroup.Horizontal = true
+	skipGroup.SetSelected(m.Skip)
+	orderEntry := widget.NewEntry()
+	orderEntry.SetPlaceHolder("default order, e.g. blender, eqgzi, azone")
+	orderEntry.SetText(strings.Join(m.Order, ", "))
+
+	extraEntry := widget.NewMultiLineEntry()
+	extraEntry.SetPlaceHolder("one file per line, relative to the zone folder")
+	extraEntry.SetText(strings.Join(m.ExtraOutputs, "\n"))
+	copyEntry := widget.NewMultiLineEntry()
+	copyEntry.SetPlaceHolder("one per line, e.g. map/*.path -> server/maps/path")
+	copies := []string{}
+	for _, cp := range m.Copies {
+		copies = append(copies, fmt.Sprintf("%s -> %s", cp.Files, cp.Target))
+	}
+	copyEntry.SetText(strings.Join(copies, "\n"))
+
+	stepsText := "No extra build steps"
+	if len(m.Steps) > 0 {
+		names := []string{}
+		for _, step := range m.Steps {
+			names = append(names, step.Name)
+		}
+		stepsText = fmt.Sprintf("Extra build steps: %s. Edit %s to change them", strings.Join(names, ", "), zone.ManifestName)
+	}
+
+	var popup *widget.PopUp
+	saveButton := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		m.LongName = strings.TrimSpace(longNameEntry.Text)
+		m.ZoneID = 0
+		if strings.TrimSpace(zoneIDEntry.Text) != "" {
+			m.ZoneID, err = strconv.Atoi(strings.TrimSpace(zoneIDEntry.Text))
+			if err != nil {
+				statusLabel.SetText(fmt.Sprintf("Failed: zone id %s is not a number", zoneIDEntry.Text))
+				return
+			}
+		}
+		m.BlenderPath = strings.TrimSpace(blenderEntry.Text)
+		m.EQGZIVersion = eqgziSelect.Selected
+		if m.EQGZIVersion == toolWorkspaceOption {
+			m.EQGZIVersion = ""
+		}
+		m.Skip = skipGroup.Selected
+		m.Order = splitList(orderEntry.Text, ",")
+		m.ExtraOutputs = splitList(extraEntry.Text, "\n")
+		m.Copies = nil
+		for _, line := range splitList(copyEntry.Text, "\n") {
+			files, target, ok := strings.Cut(line, "->")
+			if !ok {
+				statusLabel.SetText(fmt.Sprintf("Failed: copy %s should be files -> target", line))
+				return
+			}
+			m.Copies = append(m.Copies, zone.Copy{Files: strings.TrimSpace(files), Target: strings.TrimSpace(target)})
+		}
+		err := m.Save(dir)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: %s", err))
+			return
+		}
+		popup.Hide()
+		c.manifestRefresh()
+		c.logf("Saved %s/%s", zoneName, zone.ManifestName)
+	})
+	cancelButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+
+	popup = widget.NewModalPopUp(
+		container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("%s settings, saved to %s", zoneName, zone.ManifestName)),
+			container.New(
+				layout.NewFormLayout(),
+				widget.NewLabel("Long name:"),
+				longNameEntry,
+				widget.NewLabel("Zone ID:"),
+				zoneIDEntry,
+				widget.NewLabel("Blender:"),
+				blenderEntry,
+				widget.NewLabel("eqgzi:"),
+				eqgziSelect,
+				widget.NewLabel("Skip steps:"),
+				skipGroup,
+				widget.NewLabel("Step order:"),
+				orderEntry,
+				widget.NewLabel("Extra outputs:"),
+				extraEntry,
+				widget.NewLabel("Copy:"),
+				copyEntry,
+			),
+			widget.NewLabel(stepsText),
+			container.NewHBox(saveButton, cancelButton),
+			statusLabel,
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(520, 0))
+	popup.Show()
+}
+
+// manifestRefresh shows the long name and zone id of the selected zone on the settings button
+func (c *Client) manifestRefresh() {
+	c.mu.RLock()
+	zoneName := c.cfg.LastZone
+	dir := filepath.Join(c.currentPath, "zones", zoneName)
+	c.mu.RUnlock()
+
+	text := fmt.Sprintf("%s settings", zoneName)
+	m, err := zone.LoadManifest(dir)
+	if err != nil {
+		text = fmt.Sprintf("%s settings (invalid %s)", zoneName, zone.ManifestName)
+	} else if m.LongName != "" && m.ZoneID > 0 {
+		text = fmt.Sprintf("%s settings (%s, zone id %d)", zoneName, m.LongName, m.ZoneID)
+	} else if m.LongName != "" {
+		text = fmt.Sprintf("%s settings (%s)", zoneName, m.LongName)
+	}
+	c.manifestButton.SetText(text)
+}
+
+// splitList splits text by sep, dropping empty entries
+func splitList(text string, sep string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(text, sep) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		list = append(list, entry)
+	}
+	return list
+}
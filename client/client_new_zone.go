@@ -2,12 +2,13 @@ package client
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/resource"
+	"github.com/xackery/eqgzi-manager/zone"
 )
 
 func (c *Client) newZoneInit() {
@@ -16,7 +17,11 @@ func (c *Client) newZoneInit() {
 
 	c.newZoneName = widget.NewEntry()
 	c.newZoneName.OnSubmitted = func(string) { c.onNewZoneSaveButton() }
+	c.newZoneName.OnChanged = func(text string) { c.popupStatus.SetText(zoneNameHint(text)) }
+	c.newZoneTemplate = widget.NewSelect([]string{zone.DefaultTemplate}, nil)
+	c.newZoneTemplate.SetSelected(zone.DefaultTemplate)
 	c.newZoneButton = widget.NewButtonWithIcon("Create New Zone", theme.FolderNewIcon(), func() {
+		c.newZoneTemplateRefresh()
 		c.newZonePopup.Show()
 		c.window.Canvas().Focus(c.newZoneName)
 	})
@@ -25,6 +30,10 @@ func (c *Client) newZoneInit() {
 		container.NewVBox(
 			widget.NewLabel("Create a new zone"),
 			c.newZoneName,
+			container.NewHBox(
+				widget.NewLabel("Template:"),
+				c.newZoneTemplate,
+			),
 			container.NewHBox(
 				c.newZoneSaveButton,
 				c.newZoneCancelButton,
@@ -42,57 +51,49 @@ func (c *Client) onNewZoneSaveButton() {
 	}
 
 	newZone := strings.ToLower(strings.TrimSpace(c.newZoneName.Text))
-	_, err := os.Stat(fmt.Sprintf("zones/%s", newZone))
-	if err == os.ErrNotExist {
-		c.popupStatus.SetText(fmt.Sprintf("Failed: zone %s already exists", newZone))
-		return
-	}
-
-	if strings.Contains(newZone, ".") {
-		c.popupStatus.SetText(fmt.Sprintf("Failed: zone %s shouldn't have a period", newZone))
-		return
-	}
-
-	err = os.Mkdir(fmt.Sprintf("zones/%s/", newZone), os.ModePerm)
+	t, err := zone.FindTemplate(c.currentPath, c.newZoneTemplate.Selected, resource.ZoneFiles())
 	if err != nil {
-		c.popupStatus.SetText(fmt.Sprintf("Failed creating folder: %s", err))
+		c.popupStatus.SetText(fmt.Sprintf("Failed: %s", err))
 		return
 	}
-
-	err = os.WriteFile(fmt.Sprintf("zones/%s/convert.bat", newZone), convertText.Content(), os.ModePerm)
+	warnings, err := c.checkZoneName(newZone)
 	if err != nil {
-		c.popupStatus.SetText(fmt.Sprintf("Failed creating convert.bat: %s", err))
+		c.popupStatus.SetText(fmt.Sprintf("Failed: %s", err))
 		return
 	}
-
-	err = os.WriteFile(fmt.Sprintf("zones/%s/copy_eq.bat", newZone), copyEQText.Content(), os.ModePerm)
+	err = t.Create(c.currentPath, newZone)
 	if err != nil {
-		c.popupStatus.SetText(fmt.Sprintf("Failed creating copy_eq.bat: %s", err))
+		c.popupStatus.SetText(fmt.Sprintf("Failed: %s", err))
 		return
 	}
 
-	err = os.WriteFile(fmt.Sprintf("zones/%s/copy_server.bat", newZone), copyServerText.Content(), os.ModePerm)
-	if err != nil {
-		c.popupStatus.SetText(fmt.Sprintf("Failed creating copy_server.bat: %s", err))
-		return
-	}
+	c.onZoneRefresh()
+	c.zoneCombo.SetSelected(newZone)
+	c.logWarnings(warnings, "Created zones/%s from the %s template", newZone, t.Name)
+	c.newZonePopup.Hide()
+}
 
-	err = os.WriteFile(fmt.Sprintf("zones/%s/%s.blend", newZone, newZone), baseBlend.Content(), os.ModePerm)
+// newZoneTemplateRefresh lists the templates found in the templates folder
+func (c *Client) newZoneTemplateRefresh() {
+	templates, err := zone.Templates(c.currentPath, resource.ZoneFiles())
 	if err != nil {
-		c.popupStatus.SetText(fmt.Sprintf("Failed creating %s.blend: %s", newZone, err))
-		return
+		c.popupStatus.SetText(fmt.Sprintf("Failed to list templates: %s", err))
 	}
-
-	err = os.WriteFile(fmt.Sprintf("zones/%s/white.png", newZone), whitePng.Content(), os.ModePerm)
-	if err != nil {
-		c.popupStatus.SetText(fmt.Sprintf("Failed creating white.png: %s", err))
-		return
+	names := []string{}
+	for _, t := range templates {
+		names = append(names, t.Name)
 	}
-
-	c.onZoneRefresh()
-	c.zoneCombo.SetSelected(newZone)
-	c.logf("Created zones/%s", newZone)
-	c.newZonePopup.Hide()
+	c.newZoneTemplate.Options = names
+	isFound := false
+	for _, name := range names {
+		if name == c.newZoneTemplate.Selected {
+			isFound = true
+		}
+	}
+	if !isFound {
+		c.newZoneTemplate.SetSelected(zone.DefaultTemplate)
+	}
+	c.newZoneTemplate.Refresh()
 }
 
 func (c *Client) onNewZoneCancelButton() {
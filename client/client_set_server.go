@@ -16,6 +16,8 @@ func (c *Client) newSetServerInit() {
 
 	c.setServerName = widget.NewEntry()
 	c.setServerName.OnSubmitted = func(string) { c.onSetServerSaveButton() }
+	c.setServerZoneCheck = widget.NewCheck("Check new zone names against the server's zone table", nil)
+	c.setServerZoneCheck.Checked = c.cfg.IsServerZoneCheck
 	c.setServerButton = widget.NewButtonWithIcon("Set Server Path", theme.FolderNewIcon(), func() {
 		c.setServerPopup.Show()
 		c.window.Canvas().Focus(c.setServerName)
@@ -25,6 +27,7 @@ func (c *Client) newSetServerInit() {
 		container.NewVBox(
 			widget.NewLabel("Set Server Path"),
 			c.setServerName,
+			c.setServerZoneCheck,
 			container.NewHBox(
 				c.setServerSaveButton,
 				c.setServerCancelButton,
@@ -51,6 +54,7 @@ func (c *Client) onSetServerSaveButton() {
 		return
 	}
 	c.cfg.ServerPath = setServer
+	c.cfg.IsServerZoneCheck = c.setServerZoneCheck.Checked
 	c.cfg.Save()
 	c.labelServer.SetText(setServer)
 	c.logf("Updated Server Path")
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/tool"
+)
+
+const (
+	toolNewestOption    = "Newest downloaded"
+	toolWorkspaceOption = "Workspace version"
+)
+
+func (c *Client) onToolsButton() {
+	c.mu.RLock()
+	zone := c.cfg.LastZone
+	c.mu.RUnlock()
+
+	m, err := c.toolManager()
+	if err != nil {
+		c.logf("Failed tool versions: %s", err)
+		return
+	}
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapBreak
+
+	workspaceSelect := widget.NewSelect(nil, func(value string) {
+		c.mu.Lock()
+		c.cfg.EQGZIPin = value
+		if value == toolNewestOption {
+			c.cfg.EQGZIPin = ""
+		}
+		err := c.cfg.Save()
+		c.mu.Unlock()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed saving config: %s", err))
+		}
+	})
+	zoneSelect := widget.NewSelect(nil, func(value string) {
+		if value == toolWorkspaceOption {
+			value = ""
+		}
+		c.mu.Lock()
+		c.cfg.SetZoneEQGZIPin(zone, value)
+		err := c.cfg.Save()
+		c.mu.Unlock()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed saving config: %s", err))
+		}
+	})
+	lanternSelect := widget.NewSelect(nil, func(value string) {
+		c.mu.Lock()
+		c.cfg.LanternVersion = value
+		err := c.cfg.Save()
+		c.mu.Unlock()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed saving config: %s", err))
+		}
+	})
+
+	refresh := func() {
+		eqgziVersions, err := m.Versions(tool.EQGZI)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: %s", err))
+			return
+		}
+		lanternVersions, err := m.Versions(tool.Lantern)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed: %s", err))
+			return
+		}
+
+		c.mu.RLock()
+		workspacePin := c.cfg.EQGZIPin
+		zonePin := c.cfg.ZoneEQGZIPins[zone]
+		lanternVersion := c.cfg.LanternVersion
+		c.mu.RUnlock()
+
+		workspaceSelect.Options = append([]string{toolNewestOption}, eqgziVersions...)
+		zoneSelect.Options = append([]string{toolWorkspaceOption}, eqgziVersions...)
+		lanternSelect.Options = lanternVersions
+		if workspacePin == "" {
+			workspacePin = toolNewestOption
+		}
+		if zonePin == "" {
+			zonePin = toolWorkspaceOption
+		}
+		// setting the selection directly skips OnChanged, which would resave the config
+		workspaceSelect.Selected = workspacePin
+		zoneSelect.Selected = zonePin
+		lanternSelect.Selected = lanternVersion
+		workspaceSelect.Refresh()
+		zoneSelect.Refresh()
+		lanternSelect.Refresh()
+	}
+	refresh()
+
+	var downloadButton *widget.Button
+	downloadButton = widget.NewButtonWithIcon("Download latest", theme.DownloadIcon(), func() {
+		downloadButton.Disable()
+		go func() {
+			defer downloadButton.Enable()
+			for _, name := range []string{tool.EQGZI, tool.Lantern} {
+				statusLabel.SetText(fmt.Sprintf("Downloading %s", name))
+				version, err := m.Download(context.Background(), name, "")
+				if err != nil {
+					statusLabel.SetText(fmt.Sprintf("Failed %s: %s", name, err))
+					return
+				}
+				c.mu.Lock()
+				if name == tool.EQGZI {
+					c.cfg.EQGZIVersion = version
+				} else {
+					c.cfg.LanternVersion = version
+				}
+				err = c.cfg.Save()
+				c.mu.Unlock()
+				if err != nil {
+					statusLabel.SetText(fmt.Sprintf("Failed saving config: %s", err))
+					return
+				}
+			}
+			refresh()
+			statusLabel.SetText("Downloaded the latest tools")
+		}()
+	})
+
+	rollbackButton := widget.NewButtonWithIcon("Roll back eqgzi", theme.ContentUndoIcon(), func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		current := c.cfg.EQGZIVersionFor("")
+		previous, err := m.Previous(tool.EQGZI, current)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed rollback: %s", err))
+			return
+		}
+		c.cfg.EQGZIPin = previous
+		err = c.cfg.Save()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed saving config: %s", err))
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Pinned eqgzi %s, was %s", previous, current))
+		go refresh()
+	})
+
+	cleanButton := widget.NewButtonWithIcon("Clean cache", theme.DeleteIcon(), func() {
+		count, size, err := m.CleanCache()
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed cleaning cache: %s", err))
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Removed %d cached files, %.1f MB", count, float64(size)/1024/1024))
+	})
+
+	var popup *widget.PopUp
+	closeButton := widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+
+	popup = widget.NewModalPopUp(
+		container.NewVBox(
+			widget.NewLabel("Tool versions"),
+			container.New(
+				layout.NewFormLayout(),
+				widget.NewLabel("Workspace eqgzi:"),
+				workspaceSelect,
+				widget.NewLabel(fmt.Sprintf("%s eqgzi:", zone)),
+				zoneSelect,
+				widget.NewLabel("LanternExtractor:"),
+				lanternSelect,
+			),
+			container.NewHBox(downloadButton, rollbackButton, cleanButton, closeButton),
+			statusLabel,
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(420, 240))
+	popup.Show()
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/watch"
+)
+
+func (c *Client) onWatchCheck(value bool) {
+	if !value {
+		c.stopWatch()
+		return
+	}
+
+	c.mu.Lock()
+	if c.watchCancel != nil {
+		c.mu.Unlock()
+		return
+	}
+	zone := c.cfg.LastZone
+	dir := filepath.Join(c.currentPath, "zones", zone)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.watchCancel = cancel
+	c.mu.Unlock()
+
+	c.setWatchStatus(theme.VisibilityIcon(), fmt.Sprintf("Watching %s for changes", zone))
+	c.watchIcon.Show()
+	c.watchLabel.Show()
+
+	w := &watch.Watcher{
+		Dir:  dir,
+		Zone: zone,
+		OnChange: func(paths []string) bool {
+			if ctx.Err() != nil {
+				return true
+			}
+			c.mu.RLock()
+			b := build.New(c.cfg, c.currentPath, zone)
+			c.mu.RUnlock()
+			start := time.Now()
+			done := c.runBuilder(b, "build", b.Run, func() {
+				c.logf("Created %s.eqg after %s changed", zone, strings.Join(paths, ", "))
+			})
+			if done == nil {
+				c.setWatchStatus(theme.HistoryIcon(), fmt.Sprintf("Watching %s, waiting for the running build", zone))
+				return false
+			}
+			c.setWatchStatus(theme.ViewRefreshIcon(), fmt.Sprintf("Building %s, %s changed", zone, strings.Join(paths, ", ")))
+			err := <-done
+			elapsed := time.Since(start).Round(100 * time.Millisecond)
+			at := time.Now().Format("15:04:05")
+			switch {
+			case errors.Is(err, context.Canceled):
+				c.setWatchStatus(theme.WarningIcon(), fmt.Sprintf("Watching %s, last build cancelled at %s", zone, at))
+			case err != nil:
+				c.setWatchStatus(theme.ErrorIcon(), fmt.Sprintf("Watching %s, last build failed at %s after %s", zone, at, elapsed))
+			default:
+				c.setWatchStatus(theme.ConfirmIcon(), fmt.Sprintf("Watching %s, last build ok at %s in %s", zone, at, elapsed))
+			}
+			return true
+		},
+	}
+	go func() {
+		err := w.Run(ctx)
+		if err != nil {
+			c.logf("Failed watching %s: %s", zone, err)
+			c.watchCheck.SetChecked(false)
+		}
+	}()
+	c.logf("Watching %s, saving its .blend, textures or .txt files rebuilds it", zone)
+}
+
+// stopWatch stops watching the zone, if watching
+func (c *Client) stopWatch() {
+	c.mu.Lock()
+	cancel := c.watchCancel
+	c.watchCancel = nil
+	c.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	c.watchIcon.Hide()
+	c.watchLabel.Hide()
+	c.logf("Stopped watching")
+}
+
+// setWatchStatus shows the state of watch mode beside the watch check
+func (c *Client) setWatchStatus(icon fyne.Resource, text string) {
+	c.watchIcon.SetResource(icon)
+	c.watchLabel.SetText(text)
+}
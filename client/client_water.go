@@ -0,0 +1,106 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/build"
+	"github.com/xackery/eqgzi-manager/wtr"
+)
+
+func (c *Client) onWaterButton() {
+	c.mu.RLock()
+	currentPath := c.currentPath
+	zone := c.cfg.LastZone
+	c.mu.RUnlock()
+
+	mapPath := fmt.Sprintf("%s/zones/%s/map/%s.map", currentPath, zone, zone)
+	wtrPath := fmt.Sprintf("%s/zones/%s/map/%s.wtr", currentPath, zone, zone)
+	f, issues, err := build.ValidateWater(mapPath, wtrPath)
+	if f == nil {
+		c.logf("Failed water regions: %s", err)
+		return
+	}
+	issueText := ""
+	if err != nil {
+		issueText = fmt.Sprintf("Not validated: %s", err)
+	}
+
+	typeNames := []string{}
+	for _, t := range wtr.RegionTypes() {
+		typeNames = append(typeNames, t.String())
+	}
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapBreak
+	setIssues := func() {
+		lines := []string{}
+		if issueText != "" {
+			lines = append(lines, issueText)
+		}
+		for _, issue := range issues {
+			lines = append(lines, issue.String())
+		}
+		if len(lines) == 0 {
+			lines = append(lines, fmt.Sprintf("%d regions, no problems found", len(f.Regions)))
+		}
+		statusLabel.SetText(strings.Join(lines, "\n"))
+	}
+	setIssues()
+
+	rows := container.NewVBox()
+	for i, r := range f.Regions {
+		r := r
+		typeSelect := widget.NewSelect(typeNames, func(value string) {
+			t, err := wtr.ParseRegionType(value)
+			if err != nil {
+				return
+			}
+			r.Type = t
+		})
+		typeSelect.SetSelected(r.Type.String())
+		rows.Add(container.NewHBox(
+			widget.NewLabel(fmt.Sprintf("#%d", i)),
+			typeSelect,
+			widget.NewLabel(fmt.Sprintf("at (%.1f, %.1f, %.1f) size (%.1f, %.1f, %.1f)", r.Position.X, r.Position.Y, r.Position.Z, r.Extents.X, r.Extents.Y, r.Extents.Z)),
+		))
+	}
+
+	var popup *widget.PopUp
+	saveButton := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		err := f.WriteFile(wtrPath)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed saving %s.wtr: %s", zone, err))
+			return
+		}
+		issueText = ""
+		_, issues, err = build.ValidateWater(mapPath, wtrPath)
+		if err != nil {
+			issueText = fmt.Sprintf("Not validated: %s", err)
+		}
+		setIssues()
+		c.logf("Saved %s.wtr", zone)
+	})
+	closeButton := widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		popup.Hide()
+	})
+	popup = widget.NewModalPopUp(
+		container.NewBorder(
+			widget.NewLabel(fmt.Sprintf("Water regions of %s", zone)),
+			container.NewVBox(
+				statusLabel,
+				container.NewHBox(saveButton, closeButton),
+			),
+			nil,
+			nil,
+			container.NewVScroll(rows),
+		),
+		c.window.Canvas(),
+	)
+	popup.Resize(fyne.NewSize(560, 400))
+	popup.Show()
+}
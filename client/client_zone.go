@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/xackery/eqgzi-manager/zone"
+)
+
+func (c *Client) onZoneCloneButton() {
+	c.mu.RLock()
+	src := c.cfg.LastZone
+	c.mu.RUnlock()
+	if src == "" {
+		return
+	}
+	c.zoneNameDialog(fmt.Sprintf("Clone %s", src), fmt.Sprintf("%scopy", src), func(dst string) error {
+		c.mu.RLock()
+		currentPath := c.currentPath
+		c.mu.RUnlock()
+		// copying can take a while, only the config update holds the lock
+		err := zone.Clone(currentPath, src, dst)
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		version, ok := c.cfg.ZoneEQGZIPins[src]
+		if ok {
+			c.cfg.SetZoneEQGZIPin(dst, version)
+		}
+		err = c.cfg.Save()
+		if err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		return nil
+	}, func(dst string, warnings []string) {
+		c.logWarnings(warnings, "Cloned %s to %s", src, dst)
+	})
+}
+
+func (c *Client) onZoneRenameButton() {
+	c.mu.RLock()
+	oldName := c.cfg.LastZone
+	isBuilding := c.buildCancel != nil
+	c.mu.RUnlock()
+	if oldName == "" {
+		return
+	}
+	if isBuilding {
+		c.logf("Wait for the running build to finish before renaming %s", oldName)
+		return
+	}
+	c.zoneNameDialog(fmt.Sprintf("Rename %s", oldName), oldName, func(newName string) error {
+		c.mu.RLock()
+		currentPath := c.currentPath
+		c.mu.RUnlock()
+		err := zone.Rename(currentPath, oldName, newName)
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.cfg.RenameZone(oldName, newName)
+		err = c.cfg.Save()
+		if err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		return nil
+	}, func(newName string, warnings []string) {
+		c.logWarnings(warnings, "Renamed %s to %s", oldName, newName)
+	})
+}
+
+func (c *Client) onZoneDeleteButton() {
+	c.mu.RLock()
+	name := c.cfg.LastZone
+	isBuilding := c.buildCancel != nil
+	c.mu.RUnlock()
+	if name == "" {
+		return
+	}
+	if isBuilding {
+		c.logf("Wait for the running build to finish before deleting %s", name)
+		return
+	}
+	dialog.ShowConfirm("Delete zone", fmt.Sprintf("Move %s to the trash folder?", name), func(isOk bool) {
+		if !isOk {
+			return
+		}
+		c.mu.Lock()
+		trashName, err := zone.Delete(c.currentPath, name)
+		if err == nil {
+			c.cfg.RemoveZone(name)
+			err = c.cfg.Save()
+		}
+		c.mu.Unlock()
+		if err != nil {
+			c.logf("Failed delete: %s", err)
+			return
+		}
+
+		c.selectFirstZone()
+		c.logf("Moved %s to trash/%s", name, trashName)
+	}, c.window)
+}
+
+// selectFirstZone refreshes the zone list after a zone is removed and selects the first zone left
+func (c *Client) selectFirstZone() {
+	zones := c.zoneRefresh()
+	c.mu.Lock()
+	c.zoneCombo.Options = zones
+	c.mu.Unlock()
+	if len(zones) == 0 {
+		c.zoneCombo.ClearSelected()
+		c.disableActions()
+		return
+	}
+	c.zoneCombo.SetSelected(zones[0])
+}
+
+// zoneNameDialog asks for a zone name, calls apply with it, then selects the new zone and calls onSuccess
+// with any warnings about the name
+func (c *Client) zoneNameDialog(title string, value string, apply func(name string) error, onSuccess func(name string, warnings []string)) {
+	entry := widget.NewEntry()
+	entry.SetText(value)
+	entry.Validator = func(text string) error {
+		return zone.ValidateName(strings.ToLower(strings.TrimSpace(text)))
+	}
+	dialog.ShowForm(title, "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Zone", entry),
+	}, func(isOk bool) {
+		if !isOk {
+			return
+		}
+		name := strings.ToLower(strings.TrimSpace(entry.Text))
+		warnings, err := c.checkZoneName(name)
+		if err != nil {
+			c.logf("Failed: %s", err)
+			return
+		}
+		err = apply(name)
+		if err != nil {
+			c.logf("Failed: %s", err)
+			return
+		}
+		c.onZoneRefresh()
+		c.zoneCombo.SetSelected(name)
+		onSuccess(name, warnings)
+	}, c.window)
+}
+
+// checkZoneName returns an error if name breaks the client's rules, and any warnings about it.
+// The server's zone table is checked if server_zone_check is enabled
+func (c *Client) checkZoneName(name string) ([]string, error) {
+	c.mu.RLock()
+	serverPath := c.cfg.ZoneCheckPath()
+	c.mu.RUnlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return zone.CheckName(ctx, name, serverPath)
+}
+
+// zoneNameHint returns what's wrong with a zone name as it's typed, without checking the server
+func zoneNameHint(text string) string {
+	name := strings.ToLower(strings.TrimSpace(text))
+	if name == "" {
+		return ""
+	}
+	warnings, err := zone.CheckName(context.Background(), name, "")
+	if err != nil {
+		return err.Error()
+	}
+	return strings.Join(warnings, "\n")
+}
+
+// logWarnings logs a status followed by warnings, if any
+func (c *Client) logWarnings(warnings []string, format string, a ...interface{}) {
+	text := fmt.Sprintf(format, a...)
+	for _, warning := range warnings {
+		text += fmt.Sprintf("\nWarning: %s", warning)
+	}
+	c.logf("%s", text)
+}
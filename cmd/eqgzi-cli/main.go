@@ -0,0 +1,13 @@
+// eqgzi-cli runs the eqgzi-manager commands without the GUI, so it builds without cgo or a display
+package main
+
+import (
+	"os"
+
+	"github.com/xackery/eqgzi-manager/cli"
+	"github.com/xackery/eqgzi-manager/resource"
+)
+
+func main() {
+	os.Exit(cli.Run(resource.Version(), os.Args[1:]))
+}
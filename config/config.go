@@ -7,18 +7,30 @@ import (
 	"runtime"
 
 	"github.com/jbsmith7741/toml"
+	"github.com/xackery/eqgzi-manager/blender"
 )
 
 // Config represents a configuration parse
 type Config struct {
-	BlenderPath    string `toml:"blender_path" desc:"Blender Path to start Blender from"`
-	EQPath         string `toml:"eq_path" desc:"EverQuest Path to copy converted zones to"`
-	IsEQCopy       bool   `toml:"eq_copy" desc:"copy eqgzi output to eq path"`
-	LastZone       string `toml:"last_zone" desc:"Last zone selected"`
-	ServerPath     string `toml:"server_path" desc:"EQEmu Server Path, if any"`
-	IsServerCopy   bool   `toml:"server_copy" desc:"copy eqgzi output to server path"`
-	EQGZIVersion   string `toml:"eqgzi_version" desc:"Last downloaded EQGZI version"`
-	LanternVersion string `toml:"lantern_version" desc:"Last downloaded LanternExtractor version"`
+	BlenderPath                 string `toml:"blender_path" desc:"Blender Path to start Blender from"`
+	EQPath                      string `toml:"eq_path" desc:"EverQuest Path to copy converted zones to"`
+	IsEQCopy                    bool   `toml:"eq_copy" desc:"copy eqgzi output to eq path"`
+	LastZone                    string `toml:"last_zone" desc:"Last zone selected"`
+	ServerPath                  string `toml:"server_path" desc:"EQEmu Server Path, if any"`
+	IsServerCopy                bool   `toml:"server_copy" desc:"copy eqgzi output to server path"`
+	IsServerZoneCheck           bool   `toml:"server_zone_check" desc:"check new zone names against the server's zone table"`
+	EQGZIVersion                string `toml:"eqgzi_version" desc:"Last downloaded EQGZI version"`
+	LanternVersion              string `toml:"lantern_version" desc:"Last downloaded LanternExtractor version"`
+	ReleaseSource               string `toml:"release_source" desc:"Where tools and updates are downloaded from: github, http or local. Empty is github"`
+	ReleaseURL                  string `toml:"release_url" desc:"API address for github, index address for http, or folder for local release sources"`
+	EQGZIPin                    string `toml:"eqgzi_pin" desc:"EQGZI version every zone builds with, downloads won't change it. Empty uses eqgzi_version"`
+	BatchWorkers                int    `toml:"batch_workers" desc:"Zones a batch build converts at once, 0 uses the number of CPUs"`
+	MaxBlender                  int    `toml:"max_blender" desc:"Most Blender instances a batch build runs at once, 0 is one"`
+	HistoryLimit                int    `toml:"history_limit" desc:"Builds kept in each zone's history, 0 keeps 50"`
+	WinePath                    string `toml:"wine_path" desc:"Wine to run windows tools with on linux and macOS, empty runs them directly"`
+	IsUnsupportedBlenderAllowed bool   `toml:"allow_unsupported_blender" desc:"build with a Blender outside the versions eqgzi supports, with a warning"`
+	// ZoneEQGZIPins is kept last, toml writes tables after plain keys
+	ZoneEQGZIPins map[string]string `toml:"zone_eqgzi_pins" desc:"EQGZI version per zone, overriding eqgzi_pin"`
 }
 
 // NewConfig creates a new configuration
@@ -73,6 +85,58 @@ func New(ctx context.Context) (*Config, error) {
 	return &cfg, nil
 }
 
+// EQGZIVersionFor returns the eqgzi version zone builds with
+func (c *Config) EQGZIVersionFor(zone string) string {
+	version, ok := c.ZoneEQGZIPins[zone]
+	if ok && version != "" {
+		return version
+	}
+	if c.EQGZIPin != "" {
+		return c.EQGZIPin
+	}
+	return c.EQGZIVersion
+}
+
+// ZoneCheckPath returns the server path new zone names are checked against, empty if disabled
+func (c *Config) ZoneCheckPath() string {
+	if !c.IsServerZoneCheck {
+		return ""
+	}
+	return c.ServerPath
+}
+
+// SetZoneEQGZIPin pins zone to version of eqgzi, an empty version removes the pin
+func (c *Config) SetZoneEQGZIPin(zone string, version string) {
+	if version == "" {
+		delete(c.ZoneEQGZIPins, zone)
+		return
+	}
+	if c.ZoneEQGZIPins == nil {
+		c.ZoneEQGZIPins = map[string]string{}
+	}
+	c.ZoneEQGZIPins[zone] = version
+}
+
+// RenameZone moves the settings of zone oldName to newName
+func (c *Config) RenameZone(oldName string, newName string) {
+	if c.LastZone == oldName {
+		c.LastZone = newName
+	}
+	version, ok := c.ZoneEQGZIPins[oldName]
+	if ok {
+		delete(c.ZoneEQGZIPins, oldName)
+		c.ZoneEQGZIPins[newName] = version
+	}
+}
+
+// RemoveZone forgets the settings of zone name
+func (c *Config) RemoveZone(name string) {
+	if c.LastZone == name {
+		c.LastZone = ""
+	}
+	delete(c.ZoneEQGZIPins, name)
+}
+
 // Verify returns an error if configuration appears off
 func (c *Config) Verify() error {
 
@@ -81,10 +145,10 @@ func (c *Config) Verify() error {
 
 func getDefaultConfig() Config {
 	cfg := Config{}
-	if runtime.GOOS == "darwin" {
-		_, err := os.Stat("/Applications/Blender.app")
-		if err == nil {
-			cfg.BlenderPath = "/Applications/Blender.app/Contents/MacOS/Blender"
+	if runtime.GOOS != "windows" {
+		installs := blender.Find()
+		if len(installs) > 0 {
+			cfg.BlenderPath = installs[0].Path
 		}
 	}
 
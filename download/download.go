@@ -0,0 +1,143 @@
+// Package download fetches release files with resume, size and SHA-256 checks
+package download
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File downloads url to path with client, or the default client if nil. A partial download is kept as
+// path.part and resumed on the next call. The file only appears at path once it matches size and sum,
+// which are skipped when zero or empty
+func File(ctx context.Context, client *http.Client, url string, path string, size int64, sum string) error {
+	partPath := path + ".part"
+	offset := int64(0)
+	fi, err := os.Stat(partPath)
+	if err == nil {
+		offset = fi.Size()
+	}
+	if size > 0 && offset > size {
+		offset = 0
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// the server ignored the range, start over
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part file is already complete, or not part of this file
+		if Verify(partPath, size, sum) == nil {
+			return os.Rename(partPath, path)
+		}
+		os.Remove(partPath)
+		return fmt.Errorf("%s returned %s, partial download removed", filepath.Base(url), resp.Status)
+	default:
+		return fmt.Errorf("%s returned %s", filepath.Base(url), resp.Status)
+	}
+
+	w, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", filepath.Base(partPath), err)
+	}
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("download %s: %w", filepath.Base(path), err)
+	}
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("close %s: %w", filepath.Base(partPath), err)
+	}
+
+	err = Verify(partPath, size, sum)
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+	err = os.Rename(partPath, path)
+	if err != nil {
+		return fmt.Errorf("rename %s: %w", filepath.Base(partPath), err)
+	}
+	return nil
+}
+
+// Verify returns an error if the file at path does not match size and sum, which are skipped when zero or empty
+func Verify(path string, size int64, sum string) error {
+	r, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, r)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	if size > 0 && n != size {
+		return fmt.Errorf("%s is %d bytes, expected %d", filepath.Base(path), n, size)
+	}
+	if sum == "" {
+		return nil
+	}
+	fileSum := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(fileSum, sum) {
+		return fmt.Errorf("%s sha256 %s does not match %s", filepath.Base(path), fileSum, sum)
+	}
+	return nil
+}
+
+// ParseChecksums returns the SHA-256 listed for name in sha256sum output, or empty if not listed.
+// A file holding a lone sum is taken to be for name
+func ParseChecksums(r io.Reader, name string) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 1 && len(fields[0]) == sha256.Size*2 {
+			return fields[0]
+		}
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// ParseDigest returns the hex sum of a GitHub asset digest like sha256:abc, or empty for other algorithms
+func ParseDigest(digest string) string {
+	algorithm, sum, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != "sha256" {
+		return ""
+	}
+	return sum
+}
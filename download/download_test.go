@@ -0,0 +1,144 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	data := bytes.Repeat([]byte("eqgzi release zip "), 1000)
+	hash := sha256.Sum256(data)
+	sum := hex.EncodeToString(hash[:])
+	wrongSum := strings.Repeat("0", len(sum))
+
+	tests := []struct {
+		name string
+		// part is the partial download left by an earlier call
+		part []byte
+		// isRangeIgnored serves the whole file whatever range is asked for
+		isRangeIgnored bool
+		size           int64
+		sum            string
+		wantRange      string
+		isError        bool
+	}{
+		{name: "fresh", size: int64(len(data)), sum: sum},
+		{name: "unchecked", sum: ""},
+		{name: "resume", part: data[:1000], size: int64(len(data)), sum: sum, wantRange: "bytes=1000-"},
+		{name: "range ignored", part: data[:1000], isRangeIgnored: true, size: int64(len(data)), sum: sum, wantRange: "bytes=1000-"},
+		{name: "part complete", part: data, size: int64(len(data)), sum: sum, wantRange: "bytes=18000-"},
+		{name: "part too long", part: append(append([]byte{}, data...), "extra"...), size: int64(len(data)), sum: sum},
+		{name: "checksum mismatch", size: int64(len(data)), sum: wrongSum, isError: true},
+		{name: "resumed checksum mismatch", part: []byte("not the start of the file"), size: int64(len(data)), sum: sum, wantRange: "bytes=25-", isError: true},
+		{name: "size mismatch", size: int64(len(data)) + 1, sum: sum, isError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRange := ""
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRange = r.Header.Get("Range")
+				if tt.isRangeIgnored {
+					r.Header.Del("Range")
+				}
+				http.ServeContent(w, r, "tool.zip", time.Time{}, bytes.NewReader(data))
+			}))
+			defer srv.Close()
+
+			path := filepath.Join(t.TempDir(), "tool.zip")
+			if tt.part != nil {
+				err := os.WriteFile(path+".part", tt.part, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := File(context.Background(), srv.Client(), srv.URL+"/tool.zip", path, tt.size, tt.sum)
+			if gotRange != tt.wantRange {
+				t.Errorf("range = %q, want %q", gotRange, tt.wantRange)
+			}
+			_, partErr := os.Stat(path + ".part")
+			if !os.IsNotExist(partErr) {
+				t.Errorf("part file left behind: %v", partErr)
+			}
+			if tt.isError {
+				if err == nil {
+					t.Fatal("download succeeded")
+				}
+				_, statErr := os.Stat(path)
+				if !os.IsNotExist(statErr) {
+					t.Errorf("failed download left %s", filepath.Base(path))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("download: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("downloaded %d bytes, want %d", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestFileStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "tool.zip")
+	err := File(context.Background(), srv.Client(), srv.URL+"/tool.zip", path, 0, "")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want a 404 error", err)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := strings.Repeat("ab", sha256.Size)
+	other := strings.Repeat("cd", sha256.Size)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"sha256sum", other + "  other.zip\n" + sum + "  tool.zip\n", sum},
+		{"binary mode", sum + " *tool.zip\n", sum},
+		{"lone sum", sum + "\n", sum},
+		{"not listed", other + "  other.zip\n", ""},
+		{"short lone value", "abc\n", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		got := ParseChecksums(strings.NewReader(tt.text), "tool.zip")
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDigest(t *testing.T) {
+	tests := []struct {
+		digest string
+		want   string
+	}{
+		{"sha256:abc123", "abc123"},
+		{"sha512:abc123", ""},
+		{"abc123", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got := ParseDigest(tt.digest)
+		if got != tt.want {
+			t.Errorf("ParseDigest(%q) = %q, want %q", tt.digest, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package eqemu reads an EQEmu server's config and zone table
+package eqemu
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Database is the database section of eqemu_config.json
+type Database struct {
+	Host     string      `json:"host"`
+	Port     json.Number `json:"port"`
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	DB       string      `json:"db"`
+}
+
+// ZoneStatus is what the zone table knows about a short name
+type ZoneStatus struct {
+	// ZoneID is the zoneidnumber of the short name, 0 if it isn't in the table
+	ZoneID int
+	// FreeID is one past the highest zoneidnumber in the table
+	FreeID int
+}
+
+// LoadDatabase reads the database settings from serverPath/eqemu_config.json
+func LoadDatabase(serverPath string) (*Database, error) {
+	data, err := os.ReadFile(filepath.Join(serverPath, "eqemu_config.json"))
+	if err != nil {
+		return nil, fmt.Errorf("read eqemu_config.json: %w", err)
+	}
+	cfg := struct {
+		Server struct {
+			Database Database `json:"database"`
+		} `json:"server"`
+	}{}
+	err = json.Unmarshal(data, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("decode eqemu_config.json: %w", err)
+	}
+	db := cfg.Server.Database
+	if db.DB == "" {
+		return nil, fmt.Errorf("eqemu_config.json has no server.database.db")
+	}
+	return &db, nil
+}
+
+// DSN returns the mysql data source name of d
+func (d *Database) DSN() string {
+	cfg := mysql.NewConfig()
+	cfg.User = d.Username
+	cfg.Passwd = d.Password
+	cfg.Net = "tcp"
+	host := d.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	port := d.Port.String()
+	if port == "" {
+		port = "3306"
+	}
+	cfg.Addr = host + ":" + port
+	cfg.DBName = d.DB
+	cfg.Timeout = 5 * time.Second
+	return cfg.FormatDSN()
+}
+
+// CheckZone looks up shortName in the zone table of the server at serverPath
+func CheckZone(ctx context.Context, serverPath string, shortName string) (*ZoneStatus, error) {
+	d, err := LoadDatabase(serverPath)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("mysql", d.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+
+	status := &ZoneStatus{}
+	var maxID sql.NullInt64
+	err = db.QueryRowContext(ctx, "SELECT MAX(zoneidnumber) FROM zone").Scan(&maxID)
+	if err != nil {
+		return nil, fmt.Errorf("query zone ids: %w", err)
+	}
+	status.FreeID = int(maxID.Int64) + 1
+
+	err = db.QueryRowContext(ctx, "SELECT zoneidnumber FROM zone WHERE short_name = ? ORDER BY version LIMIT 1", strings.ToLower(shortName)).Scan(&status.ZoneID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("query zone %s: %w", shortName, err)
+	}
+	return status, nil
+}
+
+// Warnings returns what a zone table status means for a new zone named shortName
+func (s *ZoneStatus) Warnings(shortName string) []string {
+	if s.ZoneID != 0 {
+		return []string{fmt.Sprintf("%s is already zone id %d in the server's zone table", shortName, s.ZoneID)}
+	}
+	return []string{fmt.Sprintf("%s isn't in the server's zone table, zone id %d is free", shortName, s.FreeID)}
+}
@@ -0,0 +1,52 @@
+package eqmap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shrinkWarning is the fraction of faces a new build can lose before Compare warns
+const shrinkWarning = 0.5
+
+// Diff is the difference between two builds of a map
+type Diff struct {
+	Old, New *Map
+	// Warnings are changes likely to break pathing
+	Warnings []string
+}
+
+// Compare returns the difference from old to new
+func Compare(old *Map, new *Map) *Diff {
+	d := &Diff{Old: old, New: new}
+	if new.FaceCount() == 0 {
+		d.Warnings = append(d.Warnings, "new map has no collidable faces")
+	} else if old.FaceCount() > 0 && float64(new.FaceCount()) < float64(old.FaceCount())*(1-shrinkWarning) {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("collidable faces dropped from %d to %d", old.FaceCount(), new.FaceCount()))
+	}
+	if old.Version != new.Version {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("version changed from %d to %d", old.Version, new.Version))
+	}
+	if len(new.Models) < len(old.Models) {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("models dropped from %d to %d", len(old.Models), len(new.Models)))
+	}
+	return d
+}
+
+// String returns a multi line summary of the changes
+func (d *Diff) String() string {
+	lines := []string{
+		fmt.Sprintf("vertices: %d -> %d (%+d)", d.Old.VertexCount(), d.New.VertexCount(), d.New.VertexCount()-d.Old.VertexCount()),
+		fmt.Sprintf("faces: %d -> %d (%+d)", d.Old.FaceCount(), d.New.FaceCount(), d.New.FaceCount()-d.Old.FaceCount()),
+		fmt.Sprintf("models: %d -> %d", len(d.Old.Models), len(d.New.Models)),
+		fmt.Sprintf("placeables: %d -> %d", len(d.Old.Placeables), len(d.New.Placeables)),
+		fmt.Sprintf("bounds: %s -> %s", boundsString(d.Old), boundsString(d.New)),
+	}
+	for _, warning := range d.Warnings {
+		lines = append(lines, "warning: "+warning)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func boundsString(m *Map) string {
+	return fmt.Sprintf("(%.1f, %.1f, %.1f)-(%.1f, %.1f, %.1f)", m.Min.X, m.Min.Y, m.Min.Z, m.Max.X, m.Max.Y, m.Max.Z)
+}
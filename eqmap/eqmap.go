@@ -0,0 +1,330 @@
+// Package eqmap decodes the EQEmu collision .map files produced by azone
+package eqmap
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	version1 = 0x01000000
+	version2 = 0x02000000
+)
+
+// Vec3 is a point in zone space
+type Vec3 struct {
+	X, Y, Z float32
+}
+
+// Model is a placeable model mesh
+type Model struct {
+	Name     string
+	Vertices []Vec3
+	Faces    int
+}
+
+// Placeable is an instance of a model
+type Placeable struct {
+	Name     string
+	Position Vec3
+	Rotation Vec3
+	Scale    float32
+}
+
+// Map is a decoded collision map
+type Map struct {
+	// Version is 1 or 2
+	Version int
+	// CompressedSize and UncompressedSize are the zlib payload sizes of a version 2 map
+	CompressedSize   uint32
+	UncompressedSize uint32
+	Vertices         []Vec3
+	Indices          []uint32
+	// NonCollideVertices and NonCollideIndices are geometry that does not block movement
+	NonCollideVertices []Vec3
+	NonCollideIndices  []uint32
+	Models             []*Model
+	Placeables         []*Placeable
+	PlaceableGroups    uint32
+	TerrainTiles       uint32
+	Min                Vec3
+	Max                Vec3
+}
+
+// Open decodes the map at path
+func Open(path string) (*Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Decode reads a version 1 or 2 map from r
+func Decode(r io.Reader) (*Map, error) {
+	version := uint32(0)
+	err := binary.Read(r, binary.LittleEndian, &version)
+	if err != nil {
+		return nil, fmt.Errorf("read version: %w", err)
+	}
+	m := &Map{}
+	switch version {
+	case version1:
+		m.Version = 1
+		err = m.decodeV1(r)
+	case version2:
+		m.Version = 2
+		err = m.decodeV2(r)
+	default:
+		return nil, fmt.Errorf("unknown map version 0x%08x", version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.bounds()
+	return m, nil
+}
+
+// VertexCount returns the number of collidable and non collidable vertices
+func (m *Map) VertexCount() int {
+	return len(m.Vertices) + len(m.NonCollideVertices)
+}
+
+// FaceCount returns the number of collidable triangles
+func (m *Map) FaceCount() int {
+	return len(m.Indices) / 3
+}
+
+// NonCollideFaceCount returns the number of non collidable triangles
+func (m *Map) NonCollideFaceCount() int {
+	return len(m.NonCollideIndices) / 3
+}
+
+// Validate returns an error if the map has no usable collision geometry
+func (m *Map) Validate() error {
+	if m.FaceCount() == 0 {
+		return fmt.Errorf("map has no collidable faces")
+	}
+	if m.Min.X == m.Max.X || m.Min.Y == m.Max.Y {
+		return fmt.Errorf("map bounding box is flat")
+	}
+	return nil
+}
+
+func (m *Map) decodeV1(r io.Reader) error {
+	header := struct {
+		FaceCount     uint32
+		NodeCount     uint16
+		FacelistCount uint32
+	}{}
+	err := binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return fmt.Errorf("read v1 header: %w", err)
+	}
+	for i := uint32(0); i < header.FaceCount; i++ {
+		face := struct {
+			Vertices [3]Vec3
+			Normal   [4]float32
+		}{}
+		err = binary.Read(r, binary.LittleEndian, &face)
+		if err != nil {
+			return fmt.Errorf("read face %d of %d: %w", i, header.FaceCount, err)
+		}
+		for _, v := range face.Vertices {
+			m.Indices = append(m.Indices, uint32(len(m.Vertices)))
+			m.Vertices = append(m.Vertices, v)
+		}
+	}
+	return nil
+}
+
+func (m *Map) decodeV2(r io.Reader) error {
+	err := binary.Read(r, binary.LittleEndian, &m.CompressedSize)
+	if err != nil {
+		return fmt.Errorf("read compressed size: %w", err)
+	}
+	err = binary.Read(r, binary.LittleEndian, &m.UncompressedSize)
+	if err != nil {
+		return fmt.Errorf("read uncompressed size: %w", err)
+	}
+
+	zr, err := zlib.NewReader(io.LimitReader(r, int64(m.CompressedSize)))
+	if err != nil {
+		return fmt.Errorf("zlib: %w", err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("inflate: %w", err)
+	}
+	if uint32(len(data)) != m.UncompressedSize {
+		return fmt.Errorf("inflated %d bytes, header says %d", len(data), m.UncompressedSize)
+	}
+
+	d := &decoder{r: bytes.NewReader(data)}
+	header := struct {
+		VertexCount           uint32
+		IndexCount            uint32
+		NonCollideVertexCount uint32
+		NonCollideIndexCount  uint32
+		ModelCount            uint32
+		PlaceableCount        uint32
+		PlaceableGroupCount   uint32
+		TileCount             uint32
+		QuadsPerTile          uint32
+		UnitsPerVertex        float32
+	}{}
+	d.read(&header)
+	if d.err != nil {
+		return fmt.Errorf("read v2 header: %w", d.err)
+	}
+	m.PlaceableGroups = header.PlaceableGroupCount
+	m.TerrainTiles = header.TileCount
+
+	m.Vertices = d.vertices(header.VertexCount)
+	m.Indices = d.indices(header.IndexCount)
+	m.NonCollideVertices = d.vertices(header.NonCollideVertexCount)
+	m.NonCollideIndices = d.indices(header.NonCollideIndexCount)
+	if d.err != nil {
+		return fmt.Errorf("read geometry: %w", d.err)
+	}
+	err = checkIndices(m.Indices, len(m.Vertices))
+	if err != nil {
+		return err
+	}
+	err = checkIndices(m.NonCollideIndices, len(m.NonCollideVertices))
+	if err != nil {
+		return fmt.Errorf("non collide: %w", err)
+	}
+
+	for i := uint32(0); i < header.ModelCount; i++ {
+		model := &Model{Name: d.string()}
+		vertexCount := d.uint32()
+		faceCount := d.uint32()
+		model.Vertices = d.vertices(vertexCount)
+		for j := uint32(0); j < faceCount && d.err == nil; j++ {
+			face := struct {
+				Indices [3]uint32
+				Visible uint8
+			}{}
+			d.read(&face)
+		}
+		model.Faces = int(faceCount)
+		if d.err != nil {
+			return fmt.Errorf("read model %d of %d: %w", i, header.ModelCount, d.err)
+		}
+		m.Models = append(m.Models, model)
+	}
+
+	for i := uint32(0); i < header.PlaceableCount; i++ {
+		p := &Placeable{Name: d.string()}
+		d.read(&p.Position)
+		d.read(&p.Rotation)
+		d.read(&p.Scale)
+		if d.err != nil {
+			return fmt.Errorf("read placeable %d of %d: %w", i, header.PlaceableCount, d.err)
+		}
+		m.Placeables = append(m.Placeables, p)
+	}
+	return nil
+}
+
+// bounds sets Min and Max from every vertex
+func (m *Map) bounds() {
+	if m.VertexCount() == 0 {
+		return
+	}
+	m.Min = Vec3{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	m.Max = Vec3{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for _, vertices := range [][]Vec3{m.Vertices, m.NonCollideVertices} {
+		for _, v := range vertices {
+			m.Min.X = float32(math.Min(float64(m.Min.X), float64(v.X)))
+			m.Min.Y = float32(math.Min(float64(m.Min.Y), float64(v.Y)))
+			m.Min.Z = float32(math.Min(float64(m.Min.Z), float64(v.Z)))
+			m.Max.X = float32(math.Max(float64(m.Max.X), float64(v.X)))
+			m.Max.Y = float32(math.Max(float64(m.Max.Y), float64(v.Y)))
+			m.Max.Z = float32(math.Max(float64(m.Max.Z), float64(v.Z)))
+		}
+	}
+}
+
+func checkIndices(indices []uint32, vertexCount int) error {
+	if len(indices)%3 != 0 {
+		return fmt.Errorf("index count %d is not a multiple of 3", len(indices))
+	}
+	for i, index := range indices {
+		if int(index) >= vertexCount {
+			return fmt.Errorf("index %d references vertex %d of %d", i, index, vertexCount)
+		}
+	}
+	return nil
+}
+
+// decoder reads little endian values, keeping the first error
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *decoder) read(v interface{}) {
+	if d.err != nil {
+		return
+	}
+	d.err = binary.Read(d.r, binary.LittleEndian, v)
+}
+
+func (d *decoder) uint32() uint32 {
+	v := uint32(0)
+	d.read(&v)
+	return v
+}
+
+func (d *decoder) vertices(count uint32) []Vec3 {
+	if d.err != nil {
+		return nil
+	}
+	if int64(count)*12 > int64(d.r.Len()) {
+		d.err = fmt.Errorf("%d vertices past end of data", count)
+		return nil
+	}
+	vertices := make([]Vec3, count)
+	d.read(vertices)
+	return vertices
+}
+
+func (d *decoder) indices(count uint32) []uint32 {
+	if d.err != nil {
+		return nil
+	}
+	if int64(count)*4 > int64(d.r.Len()) {
+		d.err = fmt.Errorf("%d indices past end of data", count)
+		return nil
+	}
+	indices := make([]uint32, count)
+	d.read(indices)
+	return indices
+}
+
+func (d *decoder) string() string {
+	if d.err != nil {
+		return ""
+	}
+	value := []byte{}
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			d.err = fmt.Errorf("read string: %w", err)
+			return ""
+		}
+		if b == 0 {
+			return string(value)
+		}
+		value = append(value, b)
+	}
+}
@@ -0,0 +1,127 @@
+package eqmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+const exampleMap = "../example/zones/vergalid/map/vergalid.map"
+
+// encodeV1 writes faces as a version 1 map, without nodes or face lists
+func encodeV1(faces [][3]Vec3) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, uint32(version1))
+	binary.Write(buf, binary.LittleEndian, uint32(len(faces)))
+	binary.Write(buf, binary.LittleEndian, uint16(0))
+	binary.Write(buf, binary.LittleEndian, uint32(0))
+	for _, face := range faces {
+		binary.Write(buf, binary.LittleEndian, face)
+		binary.Write(buf, binary.LittleEndian, [4]float32{0, 0, 1, 0})
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	example, err := os.ReadFile(exampleMap)
+	if err != nil {
+		t.Fatalf("read example: %v", err)
+	}
+	square := [][3]Vec3{
+		{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}},
+		{{0, 0, 0}, {10, 10, 0}, {0, 10, 5}},
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		version      int
+		vertices     int
+		faces        int
+		models       int
+		placeables   int
+		min, max     Vec3
+		isValidError bool
+	}{
+		{"v2 example", example, 2, 40, 38, 1, 1, Vec3{-20, -20, 0}, Vec3{32, 20, 20}, false},
+		{"v1", encodeV1(square), 1, 6, 2, 0, 0, Vec3{0, 0, 0}, Vec3{10, 10, 5}, false},
+		{"v1 empty", encodeV1(nil), 1, 0, 0, 0, 0, Vec3{}, Vec3{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Decode(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if m.Version != tt.version {
+				t.Errorf("version = %d, want %d", m.Version, tt.version)
+			}
+			if m.VertexCount() != tt.vertices || m.FaceCount() != tt.faces {
+				t.Errorf("vertices, faces = %d, %d, want %d, %d", m.VertexCount(), m.FaceCount(), tt.vertices, tt.faces)
+			}
+			if len(m.Models) != tt.models || len(m.Placeables) != tt.placeables {
+				t.Errorf("models, placeables = %d, %d, want %d, %d", len(m.Models), len(m.Placeables), tt.models, tt.placeables)
+			}
+			if m.Min != tt.min || m.Max != tt.max {
+				t.Errorf("bounds = %v %v, want %v %v", m.Min, m.Max, tt.min, tt.max)
+			}
+			err = m.Validate()
+			if (err != nil) != tt.isValidError {
+				t.Errorf("validate = %v, want error %t", err, tt.isValidError)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	example, err := os.ReadFile(exampleMap)
+	if err != nil {
+		t.Fatalf("read example: %v", err)
+	}
+	square := encodeV1([][3]Vec3{{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}}})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unknown version", []byte{0, 0, 0, 3}},
+		{"v1 truncated", square[:len(square)-8]},
+		{"v2 truncated", example[:len(example)/2]},
+		{"v2 bad sizes", append(append(append([]byte{}, example[:8]...), 0, 0, 0, 0), example[12:]...)},
+	}
+	for _, tt := range tests {
+		_, err := Decode(bytes.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("%s: decoded without error", tt.name)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	m, err := Open(exampleMap)
+	if err != nil {
+		t.Fatalf("open example: %v", err)
+	}
+	empty, err := Decode(bytes.NewReader(encodeV1(nil)))
+	if err != nil {
+		t.Fatalf("decode empty: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		old, new *Map
+		warnings int
+	}{
+		{"unchanged", m, m, 0},
+		{"emptied", m, empty, 3},
+		{"filled", empty, m, 1},
+	}
+	for _, tt := range tests {
+		d := Compare(tt.old, tt.new)
+		if len(d.Warnings) != tt.warnings {
+			t.Errorf("%s: warnings = %q, want %d", tt.name, d.Warnings, tt.warnings)
+		}
+	}
+}
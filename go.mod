@@ -4,6 +4,8 @@ go 1.19
 
 require (
 	fyne.io/fyne/v2 v2.3.0
+	github.com/fsnotify/fsnotify v1.5.4
+	github.com/go-sql-driver/mysql v1.7.1
 	github.com/jbsmith7741/toml v0.3.1-0.20171003150610-484e047de162
 	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad
 )
@@ -13,7 +15,6 @@ require (
 	github.com/benoitkugler/textlayout v0.3.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fredbi/uri v0.1.0 // indirect
-	github.com/fsnotify/fsnotify v1.5.4 // indirect
 	github.com/fyne-io/gl-js v0.0.0-20220119005834-d2da28d9ccfe // indirect
 	github.com/fyne-io/glfw-js v0.0.0-20220120001248-ee7290d23504 // indirect
 	github.com/fyne-io/image v0.0.0-20220602074514-4956b0afb3d2 // indirect
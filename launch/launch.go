@@ -0,0 +1,81 @@
+// Package launch starts programs, files and folders the way each OS expects
+package launch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// openers are the commands tried, in order, to open a file or folder on linux and bsd
+var openers = [][]string{
+	{"xdg-open"},
+	{"gio", "open"},
+	{"kde-open5"},
+	{"gnome-open"},
+}
+
+// Launcher resolves the commands that run programs and open files
+type Launcher struct {
+	// WinePath runs windows .exe programs when not on windows, empty runs them directly
+	WinePath string
+}
+
+// Command returns a command running name with arg, through Wine for a windows program when WinePath is set
+func (l *Launcher) Command(name string, arg ...string) *exec.Cmd {
+	if l.isWine(name) {
+		return exec.Command(l.WinePath, append([]string{name}, arg...)...)
+	}
+	return exec.Command(name, arg...)
+}
+
+// Error explains err from starting name, suggesting Wine for a windows program that couldn't run
+func (l *Launcher) Error(name string, err error) error {
+	if err == nil || runtime.GOOS == "windows" || l.WinePath != "" || !isWindowsProgram(name) {
+		return err
+	}
+	return fmt.Errorf("%w, set wine_path in eqgzi-manager.conf to run windows programs with wine", err)
+}
+
+// isWine returns true if name is run through Wine
+func (l *Launcher) isWine(name string) bool {
+	return runtime.GOOS != "windows" && l.WinePath != "" && isWindowsProgram(name)
+}
+
+func isWindowsProgram(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".exe")
+}
+
+// OpenCommand returns a command opening path with its default application, or a folder in the file manager
+func OpenCommand(path string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("explorer.exe", filepath.FromSlash(path)), nil
+	case "darwin":
+		return exec.Command("open", path), nil
+	}
+	for _, opener := range openers {
+		_, err := exec.LookPath(opener[0])
+		if err != nil {
+			continue
+		}
+		return exec.Command(opener[0], append(opener[1:], path)...), nil
+	}
+	return nil, fmt.Errorf("no file opener found, install xdg-utils")
+}
+
+// Executable returns the program name in dir, preferring a native build over a .exe when not on windows
+func Executable(dir string, name string) string {
+	path := filepath.Join(dir, name)
+	if runtime.GOOS == "windows" {
+		return path + ".exe"
+	}
+	_, err := os.Stat(path)
+	if err == nil {
+		return path
+	}
+	return path + ".exe"
+}
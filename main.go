@@ -6,7 +6,9 @@ import (
 	"os"
 
 	"fyne.io/fyne/v2/app"
+	"github.com/xackery/eqgzi-manager/cli"
 	"github.com/xackery/eqgzi-manager/client"
+	"github.com/xackery/eqgzi-manager/resource"
 )
 
 var (
@@ -16,7 +18,10 @@ var (
 
 func main() {
 	if Version == "" {
-		Version = string(client.VersionText.Content())
+		Version = resource.Version()
+	}
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(Version, os.Args[1:]))
 	}
 	log.Println("initializing", Version)
 
@@ -0,0 +1,30 @@
+package pfs
+
+import "strings"
+
+var crcTable = func() [256]uint32 {
+	table := [256]uint32{}
+	for i := range table {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+// CRC returns the directory checksum of a file name. PFS hashes the lowercase
+// name with its null terminator using a non reflected CRC-32
+func CRC(name string) uint32 {
+	crc := uint32(0)
+	data := append([]byte(strings.ToLower(name)), 0)
+	for _, b := range data {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	return crc
+}
@@ -0,0 +1,724 @@
+package pfs
+
+import (
+	"encoding/binary"
+	"hash/adler32"
+)
+
+// This is a port of zlib's deflate at level 9 with the default window and memory settings,
+// the way eqgzi compresses blocks. Go's compress/zlib inflates the same data but encodes it
+// differently, so archives it writes don't match eqgzi's byte for byte.
+// Blocks are at most blockSize bytes, which fits the window and symbol buffer, so the window
+// never slides and each block is a single deflate block.
+
+const (
+	minMatch     = 3
+	maxMatch     = 258
+	minLookahead = maxMatch + minMatch + 1
+	windowBits   = 15
+	windowSize   = 1 << windowBits
+	windowMask   = windowSize - 1
+	maxDist      = windowSize - minLookahead
+	hashBits     = 8 + 7
+	hashSize     = 1 << hashBits
+	hashMask     = hashSize - 1
+	hashShift    = (hashBits + minMatch - 1) / minMatch
+	// level 9 configuration
+	goodMatch    = 32
+	maxLazyMatch = 258
+	niceMatch    = 258
+	maxChain     = 4096
+	tooFar       = 4096
+
+	lengthCodes = 29
+	literals    = 256
+	lCodes      = literals + 1 + lengthCodes
+	dCodes      = 30
+	blCodes     = 19
+	heapSize    = 2*lCodes + 1
+	maxBits     = 15
+	maxBLBits   = 7
+	endBlock    = 256
+	rep3To6     = 16
+	repz3To10   = 17
+	repz11To138 = 18
+
+	storedBlock = 0
+	staticTrees = 1
+	dynTrees    = 2
+)
+
+var (
+	extraLBits  = [lengthCodes]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 0}
+	extraDBits  = [dCodes]int{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13}
+	extraBLBits = [blCodes]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 3, 7}
+	blOrder     = [blCodes]int{16, 17, 18, 0, 8, 7, 9, 6, 10, 5, 11, 4, 12, 3, 13, 2, 14, 1, 15}
+
+	staticLTree [lCodes + 2]ctData
+	staticDTree [dCodes]ctData
+	distCode    [512]uint8
+	lengthCode  [maxMatch - minMatch + 1]uint8
+	baseLength  [lengthCodes]int
+	baseDist    [dCodes]int
+)
+
+// ctData is a tree node. As in zlib, freq and code share fc, dad and len share dl
+type ctData struct {
+	fc uint16
+	dl uint16
+}
+
+// treeDesc describes a dynamic tree and its static counterpart
+type treeDesc struct {
+	tree       []ctData
+	maxCode    int
+	staticTree []ctData
+	extraBits  []int
+	extraBase  int
+	elems      int
+	maxLength  int
+}
+
+func init() {
+	length := 0
+	code := 0
+	for code = 0; code < lengthCodes-1; code++ {
+		baseLength[code] = length
+		for n := 0; n < 1<<extraLBits[code]; n++ {
+			lengthCode[length] = uint8(code)
+			length++
+		}
+	}
+	// length 258 is code 28, overwriting the last length of code 27
+	lengthCode[length-1] = uint8(code)
+
+	dist := 0
+	for code = 0; code < 16; code++ {
+		baseDist[code] = dist
+		for n := 0; n < 1<<extraDBits[code]; n++ {
+			distCode[dist] = uint8(code)
+			dist++
+		}
+	}
+	dist >>= 7
+	for ; code < dCodes; code++ {
+		baseDist[code] = dist << 7
+		for n := 0; n < 1<<(extraDBits[code]-7); n++ {
+			distCode[256+dist] = uint8(code)
+			dist++
+		}
+	}
+
+	blCount := make([]uint16, maxBits+1)
+	n := 0
+	for ; n <= 143; n++ {
+		staticLTree[n].dl = 8
+		blCount[8]++
+	}
+	for ; n <= 255; n++ {
+		staticLTree[n].dl = 9
+		blCount[9]++
+	}
+	for ; n <= 279; n++ {
+		staticLTree[n].dl = 7
+		blCount[7]++
+	}
+	for ; n <= 287; n++ {
+		staticLTree[n].dl = 8
+		blCount[8]++
+	}
+	genCodes(staticLTree[:], lCodes+1, blCount)
+	for n := 0; n < dCodes; n++ {
+		staticDTree[n].dl = 5
+		staticDTree[n].fc = uint16(bitReverse(uint(n), 5))
+	}
+}
+
+func dCode(dist int) int {
+	if dist < 256 {
+		return int(distCode[dist])
+	}
+	return int(distCode[256+(dist>>7)])
+}
+
+func bitReverse(code uint, length int) uint {
+	res := uint(0)
+	for {
+		res |= code & 1
+		code >>= 1
+		res <<= 1
+		length--
+		if length <= 0 {
+			break
+		}
+	}
+	return res >> 1
+}
+
+// genCodes assigns codes to the lengths of tree
+func genCodes(tree []ctData, maxCode int, blCount []uint16) {
+	nextCode := [maxBits + 1]uint16{}
+	code := uint(0)
+	for bits := 1; bits <= maxBits; bits++ {
+		code = (code + uint(blCount[bits-1])) << 1
+		nextCode[bits] = uint16(code)
+	}
+	for n := 0; n <= maxCode; n++ {
+		length := int(tree[n].dl)
+		if length == 0 {
+			continue
+		}
+		tree[n].fc = uint16(bitReverse(uint(nextCode[length]), length))
+		nextCode[length]++
+	}
+}
+
+// symbol is a literal (dist 0) or a match of length lc+minMatch at dist
+type symbol struct {
+	dist int
+	lc   int
+}
+
+// deflater holds the state of compressing a single block
+type deflater struct {
+	window    []byte
+	head      []uint16
+	prev      []uint16
+	insH      int
+	strStart  int
+	lookahead int
+
+	matchLength    int
+	prevLength     int
+	matchAvailable bool
+	matchStart     int
+	prevMatch      int
+
+	dynLTree [heapSize]ctData
+	dynDTree [2*dCodes + 1]ctData
+	blTree   [2*blCodes + 1]ctData
+	lDesc    treeDesc
+	dDesc    treeDesc
+	blDesc   treeDesc
+
+	blCount  [maxBits + 1]uint16
+	heap     [heapSize]int
+	heapLen  int
+	heapMax  int
+	depth    [heapSize]uint8
+	symbols  []symbol
+	optLen   int64
+	static   int64
+	out      []byte
+	bitBuf   uint64
+	bitCount uint
+}
+
+// compress returns data as a zlib stream identical to zlib's compress2 at level 9
+func compress(data []byte) []byte {
+	d := &deflater{
+		window: make([]byte, len(data)+maxMatch+minMatch+1),
+		head:   make([]uint16, hashSize),
+		prev:   make([]uint16, windowSize),
+	}
+	copy(d.window, data)
+	d.lDesc = treeDesc{tree: d.dynLTree[:], staticTree: staticLTree[:], extraBits: extraLBits[:], extraBase: literals + 1, elems: lCodes, maxLength: maxBits}
+	d.dDesc = treeDesc{tree: d.dynDTree[:], staticTree: staticDTree[:], extraBits: extraDBits[:], elems: dCodes, maxLength: maxBits}
+	d.blDesc = treeDesc{tree: d.blTree[:], extraBits: extraBLBits[:], elems: blCodes, maxLength: maxBLBits}
+	d.dynLTree[endBlock].fc = 1
+
+	// header for a 32K window at the best compression level
+	d.out = append(d.out, 0x78, 0xda)
+	d.lookahead = len(data)
+	if d.lookahead >= minMatch {
+		d.insH = int(d.window[0])
+		d.updateHash(d.window[1])
+	}
+	d.deflateSlow()
+	d.flushBlock(data)
+	d.out = binary.BigEndian.AppendUint32(d.out, adler32.Checksum(data))
+	return d.out
+}
+
+func (d *deflater) updateHash(c byte) {
+	d.insH = ((d.insH << hashShift) ^ int(c)) & hashMask
+}
+
+// insertString adds the string at pos to the hash chains and returns the previous head of its chain
+func (d *deflater) insertString(pos int) int {
+	d.updateHash(d.window[pos+minMatch-1])
+	head := int(d.head[d.insH])
+	d.prev[pos&windowMask] = uint16(head)
+	d.head[d.insH] = uint16(pos)
+	return head
+}
+
+// deflateSlow finds matches with lazy evaluation, as zlib does for levels 4 to 9
+func (d *deflater) deflateSlow() {
+	d.matchLength = minMatch - 1
+	d.prevLength = minMatch - 1
+	for d.lookahead > 0 {
+		hashHead := 0
+		if d.lookahead >= minMatch {
+			hashHead = d.insertString(d.strStart)
+		}
+		d.prevLength = d.matchLength
+		d.prevMatch = d.matchStart
+		d.matchLength = minMatch - 1
+
+		// position 0 doubles as the empty chain marker, as in zlib
+		if hashHead != 0 && d.prevLength < maxLazyMatch && d.strStart-hashHead <= maxDist {
+			d.matchLength = d.longestMatch(hashHead)
+			if d.matchLength == minMatch && d.strStart-d.matchStart > tooFar {
+				d.matchLength = minMatch - 1
+			}
+		}
+
+		if d.prevLength >= minMatch && d.matchLength <= d.prevLength {
+			maxInsert := d.strStart + d.lookahead - minMatch
+			d.tallyDist(d.strStart-1-d.prevMatch, d.prevLength-minMatch)
+			d.lookahead -= d.prevLength - 1
+			d.prevLength -= 2
+			for {
+				d.strStart++
+				if d.strStart <= maxInsert {
+					d.insertString(d.strStart)
+				}
+				d.prevLength--
+				if d.prevLength == 0 {
+					break
+				}
+			}
+			d.matchAvailable = false
+			d.matchLength = minMatch - 1
+			d.strStart++
+		} else if d.matchAvailable {
+			d.tallyLiteral(d.window[d.strStart-1])
+			d.strStart++
+			d.lookahead--
+		} else {
+			d.matchAvailable = true
+			d.strStart++
+			d.lookahead--
+		}
+	}
+	if d.matchAvailable {
+		d.tallyLiteral(d.window[d.strStart-1])
+		d.matchAvailable = false
+	}
+}
+
+// longestMatch returns the length of the longest match along the hash chain starting at curMatch
+func (d *deflater) longestMatch(curMatch int) int {
+	chainLength := maxChain
+	scan := d.strStart
+	bestLen := d.prevLength
+	nice := niceMatch
+	limit := 0
+	if d.strStart > maxDist {
+		limit = d.strStart - maxDist
+	}
+	w := d.window
+	scanEnd1 := w[scan+bestLen-1]
+	scanEnd := w[scan+bestLen]
+	if d.prevLength >= goodMatch {
+		chainLength >>= 2
+	}
+	if nice > d.lookahead {
+		nice = d.lookahead
+	}
+	for {
+		match := curMatch
+		if w[match+bestLen] == scanEnd && w[match+bestLen-1] == scanEnd1 &&
+			w[match] == w[scan] && w[match+1] == w[scan+1] {
+			// the third byte always matches when the hashes and first two bytes do
+			length := 2
+			for {
+				isEqual := true
+				for i := 0; i < 8; i++ {
+					length++
+					if w[scan+length] != w[match+length] {
+						isEqual = false
+						break
+					}
+				}
+				if !isEqual || length >= maxMatch {
+					break
+				}
+			}
+			if length > bestLen {
+				d.matchStart = curMatch
+				bestLen = length
+				if length >= nice {
+					break
+				}
+				scanEnd1 = w[scan+bestLen-1]
+				scanEnd = w[scan+bestLen]
+			}
+		}
+		curMatch = int(d.prev[curMatch&windowMask])
+		chainLength--
+		if curMatch <= limit || chainLength == 0 {
+			break
+		}
+	}
+	if bestLen <= d.lookahead {
+		return bestLen
+	}
+	return d.lookahead
+}
+
+func (d *deflater) tallyLiteral(c byte) {
+	d.symbols = append(d.symbols, symbol{lc: int(c)})
+	d.dynLTree[c].fc++
+}
+
+func (d *deflater) tallyDist(dist int, length int) {
+	d.symbols = append(d.symbols, symbol{dist: dist, lc: length})
+	d.dynLTree[int(lengthCode[length])+literals+1].fc++
+	d.dynDTree[dCode(dist-1)].fc++
+}
+
+// flushBlock writes the symbols as the last block, choosing stored, static or dynamic trees as zlib does
+func (d *deflater) flushBlock(data []byte) {
+	d.buildTree(&d.lDesc)
+	d.buildTree(&d.dDesc)
+	maxBLIndex := d.buildBLTree()
+	optLenB := (d.optLen + 3 + 7) >> 3
+	staticLenB := (d.static + 3 + 7) >> 3
+	if staticLenB <= optLenB {
+		optLenB = staticLenB
+	}
+
+	switch {
+	case int64(len(data))+4 <= optLenB:
+		d.sendBits(storedBlock<<1+1, 3)
+		d.windup()
+		d.out = binary.LittleEndian.AppendUint16(d.out, uint16(len(data)))
+		d.out = binary.LittleEndian.AppendUint16(d.out, ^uint16(len(data)))
+		d.out = append(d.out, data...)
+	case staticLenB == optLenB:
+		d.sendBits(staticTrees<<1+1, 3)
+		d.compressBlock(staticLTree[:], staticDTree[:])
+	default:
+		d.sendBits(dynTrees<<1+1, 3)
+		d.sendAllTrees(d.lDesc.maxCode+1, d.dDesc.maxCode+1, maxBLIndex+1)
+		d.compressBlock(d.dynLTree[:], d.dynDTree[:])
+	}
+	d.windup()
+}
+
+func (d *deflater) smaller(tree []ctData, n int, m int) bool {
+	return tree[n].fc < tree[m].fc || (tree[n].fc == tree[m].fc && d.depth[n] <= d.depth[m])
+}
+
+func (d *deflater) pqDownHeap(tree []ctData, k int) {
+	v := d.heap[k]
+	j := k << 1
+	for j <= d.heapLen {
+		if j < d.heapLen && d.smaller(tree, d.heap[j+1], d.heap[j]) {
+			j++
+		}
+		if d.smaller(tree, v, d.heap[j]) {
+			break
+		}
+		d.heap[k] = d.heap[j]
+		k = j
+		j <<= 1
+	}
+	d.heap[k] = v
+}
+
+// buildTree builds the Huffman tree of desc and sets its code lengths and codes
+func (d *deflater) buildTree(desc *treeDesc) {
+	tree := desc.tree
+	maxCode := -1
+	d.heapLen = 0
+	d.heapMax = heapSize
+	for n := 0; n < desc.elems; n++ {
+		if tree[n].fc != 0 {
+			d.heapLen++
+			d.heap[d.heapLen] = n
+			maxCode = n
+			d.depth[n] = 0
+		} else {
+			tree[n].dl = 0
+		}
+	}
+	// force at least two codes of non zero frequency
+	for d.heapLen < 2 {
+		node := 0
+		if maxCode < 2 {
+			maxCode++
+			node = maxCode
+		}
+		d.heapLen++
+		d.heap[d.heapLen] = node
+		tree[node].fc = 1
+		d.depth[node] = 0
+		d.optLen--
+		if desc.staticTree != nil {
+			d.static -= int64(desc.staticTree[node].dl)
+		}
+	}
+	desc.maxCode = maxCode
+
+	for n := d.heapLen / 2; n >= 1; n-- {
+		d.pqDownHeap(tree, n)
+	}
+	node := desc.elems
+	for {
+		n := d.heap[1]
+		d.heap[1] = d.heap[d.heapLen]
+		d.heapLen--
+		d.pqDownHeap(tree, 1)
+		m := d.heap[1]
+
+		d.heapMax--
+		d.heap[d.heapMax] = n
+		d.heapMax--
+		d.heap[d.heapMax] = m
+
+		tree[node].fc = tree[n].fc + tree[m].fc
+		depth := d.depth[n]
+		if d.depth[m] > depth {
+			depth = d.depth[m]
+		}
+		d.depth[node] = depth + 1
+		tree[n].dl = uint16(node)
+		tree[m].dl = uint16(node)
+		d.heap[1] = node
+		node++
+		d.pqDownHeap(tree, 1)
+		if d.heapLen < 2 {
+			break
+		}
+	}
+	d.heapMax--
+	d.heap[d.heapMax] = d.heap[1]
+
+	d.genBitLen(desc)
+	genCodes(tree, maxCode, d.blCount[:])
+}
+
+// genBitLen computes the code lengths of desc, limiting them to its max length
+func (d *deflater) genBitLen(desc *treeDesc) {
+	tree := desc.tree
+	overflow := 0
+	for bits := range d.blCount {
+		d.blCount[bits] = 0
+	}
+	tree[d.heap[d.heapMax]].dl = 0
+	h := d.heapMax + 1
+	for ; h < heapSize; h++ {
+		n := d.heap[h]
+		bits := int(tree[tree[n].dl].dl) + 1
+		if bits > desc.maxLength {
+			bits = desc.maxLength
+			overflow++
+		}
+		tree[n].dl = uint16(bits)
+		if n > desc.maxCode {
+			continue
+		}
+		d.blCount[bits]++
+		xbits := 0
+		if n >= desc.extraBase {
+			xbits = desc.extraBits[n-desc.extraBase]
+		}
+		f := int64(tree[n].fc)
+		d.optLen += f * int64(bits+xbits)
+		if desc.staticTree != nil {
+			d.static += f * int64(int(desc.staticTree[n].dl)+xbits)
+		}
+	}
+	if overflow == 0 {
+		return
+	}
+
+	for overflow > 0 {
+		bits := desc.maxLength - 1
+		for d.blCount[bits] == 0 {
+			bits--
+		}
+		d.blCount[bits]--
+		d.blCount[bits+1] += 2
+		d.blCount[desc.maxLength]--
+		overflow -= 2
+	}
+	for bits := desc.maxLength; bits != 0; bits-- {
+		n := int(d.blCount[bits])
+		for n != 0 {
+			h--
+			m := d.heap[h]
+			if m > desc.maxCode {
+				continue
+			}
+			if int(tree[m].dl) != bits {
+				d.optLen += (int64(bits) - int64(tree[m].dl)) * int64(tree[m].fc)
+				tree[m].dl = uint16(bits)
+			}
+			n--
+		}
+	}
+}
+
+// scanTree counts the code lengths of tree in the bit length tree
+func (d *deflater) scanTree(tree []ctData, maxCode int) {
+	prevLen := -1
+	nextLen := int(tree[0].dl)
+	count := 0
+	maxCount, minCount := 7, 4
+	if nextLen == 0 {
+		maxCount, minCount = 138, 3
+	}
+	tree[maxCode+1].dl = 0xffff
+	for n := 0; n <= maxCode; n++ {
+		curLen := nextLen
+		nextLen = int(tree[n+1].dl)
+		count++
+		if count < maxCount && curLen == nextLen {
+			continue
+		} else if count < minCount {
+			d.blTree[curLen].fc += uint16(count)
+		} else if curLen != 0 {
+			if curLen != prevLen {
+				d.blTree[curLen].fc++
+			}
+			d.blTree[rep3To6].fc++
+		} else if count <= 10 {
+			d.blTree[repz3To10].fc++
+		} else {
+			d.blTree[repz11To138].fc++
+		}
+		count = 0
+		prevLen = curLen
+		switch {
+		case nextLen == 0:
+			maxCount, minCount = 138, 3
+		case curLen == nextLen:
+			maxCount, minCount = 6, 3
+		default:
+			maxCount, minCount = 7, 4
+		}
+	}
+}
+
+// sendTree writes the code lengths of tree using the bit length tree
+func (d *deflater) sendTree(tree []ctData, maxCode int) {
+	prevLen := -1
+	nextLen := int(tree[0].dl)
+	count := 0
+	maxCount, minCount := 7, 4
+	if nextLen == 0 {
+		maxCount, minCount = 138, 3
+	}
+	for n := 0; n <= maxCode; n++ {
+		curLen := nextLen
+		nextLen = int(tree[n+1].dl)
+		count++
+		if count < maxCount && curLen == nextLen {
+			continue
+		} else if count < minCount {
+			for ; count != 0; count-- {
+				d.sendCode(curLen, d.blTree[:])
+			}
+		} else if curLen != 0 {
+			if curLen != prevLen {
+				d.sendCode(curLen, d.blTree[:])
+				count--
+			}
+			d.sendCode(rep3To6, d.blTree[:])
+			d.sendBits(uint(count-3), 2)
+		} else if count <= 10 {
+			d.sendCode(repz3To10, d.blTree[:])
+			d.sendBits(uint(count-3), 3)
+		} else {
+			d.sendCode(repz11To138, d.blTree[:])
+			d.sendBits(uint(count-11), 7)
+		}
+		count = 0
+		prevLen = curLen
+		switch {
+		case nextLen == 0:
+			maxCount, minCount = 138, 3
+		case curLen == nextLen:
+			maxCount, minCount = 6, 3
+		default:
+			maxCount, minCount = 7, 4
+		}
+	}
+}
+
+// buildBLTree builds the bit length tree and returns the index in blOrder of the last code to send
+func (d *deflater) buildBLTree() int {
+	d.scanTree(d.dynLTree[:], d.lDesc.maxCode)
+	d.scanTree(d.dynDTree[:], d.dDesc.maxCode)
+	d.buildTree(&d.blDesc)
+	maxBLIndex := blCodes - 1
+	for ; maxBLIndex >= 3; maxBLIndex-- {
+		if d.blTree[blOrder[maxBLIndex]].dl != 0 {
+			break
+		}
+	}
+	d.optLen += 3*(int64(maxBLIndex)+1) + 5 + 5 + 4
+	return maxBLIndex
+}
+
+func (d *deflater) sendAllTrees(lCodes int, dCodes int, blCodes int) {
+	d.sendBits(uint(lCodes-257), 5)
+	d.sendBits(uint(dCodes-1), 5)
+	d.sendBits(uint(blCodes-4), 4)
+	for rank := 0; rank < blCodes; rank++ {
+		d.sendBits(uint(d.blTree[blOrder[rank]].dl), 3)
+	}
+	d.sendTree(d.dynLTree[:], lCodes-1)
+	d.sendTree(d.dynDTree[:], dCodes-1)
+}
+
+func (d *deflater) compressBlock(lTree []ctData, dTree []ctData) {
+	for _, s := range d.symbols {
+		if s.dist == 0 {
+			d.sendCode(s.lc, lTree)
+			continue
+		}
+		code := int(lengthCode[s.lc])
+		d.sendCode(code+literals+1, lTree)
+		extra := extraLBits[code]
+		if extra != 0 {
+			d.sendBits(uint(s.lc-baseLength[code]), extra)
+		}
+		dist := s.dist - 1
+		code = dCode(dist)
+		d.sendCode(code, dTree)
+		extra = extraDBits[code]
+		if extra != 0 {
+			d.sendBits(uint(dist-baseDist[code]), extra)
+		}
+	}
+	d.sendCode(endBlock, lTree)
+}
+
+func (d *deflater) sendCode(c int, tree []ctData) {
+	d.sendBits(uint(tree[c].fc), int(tree[c].dl))
+}
+
+// sendBits writes the low length bits of value, least significant bit first
+func (d *deflater) sendBits(value uint, length int) {
+	d.bitBuf |= uint64(value) << d.bitCount
+	d.bitCount += uint(length)
+	for d.bitCount >= 8 {
+		d.out = append(d.out, byte(d.bitBuf))
+		d.bitBuf >>= 8
+		d.bitCount -= 8
+	}
+}
+
+// windup flushes the remaining bits, aligning the output to a byte
+func (d *deflater) windup() {
+	if d.bitCount > 0 {
+		d.out = append(d.out, byte(d.bitBuf))
+	}
+	d.bitBuf = 0
+	d.bitCount = 0
+}
@@ -0,0 +1,302 @@
+// Package pfs reads and writes PFS archives, the container format of EverQuest .eqg, .s3d and .pfs files
+package pfs
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// Version is the PFS version written by eqgzi
+	Version = 0x20000
+	// filenameCRC is the CRC of the entry holding the filename table
+	filenameCRC = 0x61580AC9
+	// blockSize is the maximum inflated size of a data block
+	blockSize = 8192
+)
+
+var magic = [4]byte{'P', 'F', 'S', ' '}
+
+// Entry is a file stored in an archive
+type Entry struct {
+	Name string
+	CRC  uint32
+	// Offset is the position of the first data block in the archive
+	Offset uint32
+	// Size is the inflated size
+	Size uint32
+	// CompressedSize is the total size of the data blocks, including block headers
+	CompressedSize uint32
+}
+
+// Archive is an opened PFS archive
+type Archive struct {
+	r       io.ReaderAt
+	closer  io.Closer
+	size    int64
+	Version uint32
+	Entries []*Entry
+}
+
+// Open opens the archive at path
+func Open(path string) (*Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("stat: %w", err)
+	}
+	a, err := NewReader(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a.closer = f
+	return a, nil
+}
+
+// NewReader reads the directory of an archive of size bytes from r
+func NewReader(r io.ReaderAt, size int64) (*Archive, error) {
+	a := &Archive{r: r, size: size}
+
+	header := make([]byte, 12)
+	_, err := r.ReadAt(header, 0)
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if !bytes.Equal(header[4:8], magic[:]) {
+		return nil, fmt.Errorf("not a pfs archive, magic %q", header[4:8])
+	}
+	dirOffset := binary.LittleEndian.Uint32(header[0:4])
+	a.Version = binary.LittleEndian.Uint32(header[8:12])
+	if int64(dirOffset)+4 > size {
+		return nil, fmt.Errorf("directory offset %d past end of file", dirOffset)
+	}
+
+	countData := make([]byte, 4)
+	_, err = r.ReadAt(countData, int64(dirOffset))
+	if err != nil {
+		return nil, fmt.Errorf("read directory count: %w", err)
+	}
+	count := binary.LittleEndian.Uint32(countData)
+	if int64(dirOffset)+4+int64(count)*12 > size {
+		return nil, fmt.Errorf("directory of %d entries past end of file", count)
+	}
+
+	dirData := make([]byte, count*12)
+	_, err = r.ReadAt(dirData, int64(dirOffset)+4)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	var table *Entry
+	entries := []*Entry{}
+	for i := uint32(0); i < count; i++ {
+		e := &Entry{
+			CRC:    binary.LittleEndian.Uint32(dirData[i*12:]),
+			Offset: binary.LittleEndian.Uint32(dirData[i*12+4:]),
+			Size:   binary.LittleEndian.Uint32(dirData[i*12+8:]),
+		}
+		e.CompressedSize, err = a.compressedSize(e)
+		if err != nil {
+			return nil, fmt.Errorf("entry 0x%08x: %w", e.CRC, err)
+		}
+		if e.CRC == filenameCRC {
+			table = e
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if table == nil {
+		return nil, fmt.Errorf("filename table not found")
+	}
+
+	names, err := a.readNames(table)
+	if err != nil {
+		return nil, fmt.Errorf("filename table: %w", err)
+	}
+	if len(names) != len(entries) {
+		return nil, fmt.Errorf("filename table has %d names for %d entries", len(names), len(entries))
+	}
+
+	// names are matched to entries by CRC, offsets can't tell apart empty files that share one
+	byCRC := map[uint32][]*Entry{}
+	for _, e := range entries {
+		byCRC[e.CRC] = append(byCRC[e.CRC], e)
+	}
+	a.Entries = []*Entry{}
+	for _, name := range names {
+		crc := CRC(name)
+		matches := byCRC[crc]
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s crc 0x%08x not found in directory", name, crc)
+		}
+		e := matches[0]
+		byCRC[crc] = matches[1:]
+		e.Name = name
+		a.Entries = append(a.Entries, e)
+	}
+	// names are stored in the order their data appears in the archive, keep that order for equal offsets
+	sort.SliceStable(a.Entries, func(i, j int) bool { return a.Entries[i].Offset < a.Entries[j].Offset })
+	return a, nil
+}
+
+// CheckName returns an error if name is absolute or climbs out of the folder it is read from or written to
+func CheckName(name string) error {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	// a drive letter is absolute on windows, whichever OS wrote the archive
+	if filepath.IsAbs(name) || strings.HasPrefix(slashed, "/") || len(slashed) > 1 && slashed[1] == ':' {
+		return fmt.Errorf("%s is an absolute path", name)
+	}
+	for _, part := range strings.Split(slashed, "/") {
+		if part == ".." {
+			return fmt.Errorf("%s climbs out of its folder", name)
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying file if the archive was opened with Open
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Entry returns the entry named name, or nil. Names are case insensitive
+func (a *Archive) Entry(name string) *Entry {
+	name = strings.ToLower(name)
+	for _, e := range a.Entries {
+		if strings.ToLower(e.Name) == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// ReadFile returns the inflated content of the entry named name
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	e := a.Entry(name)
+	if e == nil {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return a.read(e)
+}
+
+// Extract writes the entry named name to dst
+func (a *Archive) Extract(name string, dst string) error {
+	data, err := a.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(dst, data, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// read inflates the data blocks of e
+func (a *Archive) read(e *Entry) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, e.Size))
+	offset := int64(e.Offset)
+	blockHeader := make([]byte, 8)
+	for uint32(out.Len()) < e.Size {
+		_, err := a.r.ReadAt(blockHeader, offset)
+		if err != nil {
+			return nil, fmt.Errorf("read block header at %d: %w", offset, err)
+		}
+		deflatedLen := binary.LittleEndian.Uint32(blockHeader[0:4])
+		inflatedLen := binary.LittleEndian.Uint32(blockHeader[4:8])
+		offset += 8
+		if offset+int64(deflatedLen) > a.size {
+			return nil, fmt.Errorf("block at %d past end of file", offset)
+		}
+
+		zr, err := zlib.NewReader(io.NewSectionReader(a.r, offset, int64(deflatedLen)))
+		if err != nil {
+			return nil, fmt.Errorf("block at %d: %w", offset, err)
+		}
+		n, err := io.Copy(out, zr)
+		zr.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inflate block at %d: %w", offset, err)
+		}
+		if n != int64(inflatedLen) {
+			return nil, fmt.Errorf("block at %d inflated to %d bytes, expected %d", offset, n, inflatedLen)
+		}
+		offset += int64(deflatedLen)
+	}
+	if uint32(out.Len()) != e.Size {
+		return nil, fmt.Errorf("inflated to %d bytes, expected %d", out.Len(), e.Size)
+	}
+	return out.Bytes(), nil
+}
+
+// compressedSize walks the block headers of e without inflating them
+func (a *Archive) compressedSize(e *Entry) (uint32, error) {
+	offset := int64(e.Offset)
+	total := uint32(0)
+	inflated := uint32(0)
+	blockHeader := make([]byte, 8)
+	for inflated < e.Size {
+		_, err := a.r.ReadAt(blockHeader, offset)
+		if err != nil {
+			return 0, fmt.Errorf("read block header at %d: %w", offset, err)
+		}
+		deflatedLen := binary.LittleEndian.Uint32(blockHeader[0:4])
+		inflatedLen := binary.LittleEndian.Uint32(blockHeader[4:8])
+		if inflatedLen == 0 {
+			return 0, fmt.Errorf("empty block at %d", offset)
+		}
+		offset += 8 + int64(deflatedLen)
+		if offset > a.size {
+			return 0, fmt.Errorf("block past end of file")
+		}
+		total += 8 + deflatedLen
+		inflated += inflatedLen
+	}
+	return total, nil
+}
+
+func (a *Archive) readNames(table *Entry) ([]string, error) {
+	data, err := a.read(table)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(data)
+	count := uint32(0)
+	err = binary.Read(r, binary.LittleEndian, &count)
+	if err != nil {
+		return nil, fmt.Errorf("read count: %w", err)
+	}
+	names := []string{}
+	for i := uint32(0); i < count; i++ {
+		nameLen := uint32(0)
+		err = binary.Read(r, binary.LittleEndian, &nameLen)
+		if err != nil {
+			return nil, fmt.Errorf("read name %d length: %w", i, err)
+		}
+		if int64(nameLen) > int64(r.Len()) {
+			return nil, fmt.Errorf("name %d length %d past end of table", i, nameLen)
+		}
+		name := make([]byte, nameLen)
+		_, err = io.ReadFull(r, name)
+		if err != nil {
+			return nil, fmt.Errorf("read name %d: %w", i, err)
+		}
+		names = append(names, strings.TrimRight(string(name), "\x00"))
+	}
+	return names, nil
+}
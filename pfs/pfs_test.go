@@ -0,0 +1,167 @@
+package pfs
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+const exampleEQG = "../example/zones/vergalid/out/vergalid.eqg"
+
+func TestCRC(t *testing.T) {
+	tests := []struct {
+		name string
+		want uint32
+	}{
+		{"vergalid.ter", 0x01CB3594},
+		{"black.png", 0xAB662521},
+		{"wood_floor.png", 0xD61D0F16},
+		{"vergalid.zon", 0xEA870D9C},
+		{"VERGALID.ZON", 0xEA870D9C},
+	}
+	for _, tt := range tests {
+		if got := CRC(tt.name); got != tt.want {
+			t.Errorf("CRC(%q) = 0x%08X, want 0x%08X", tt.name, got, tt.want)
+		}
+	}
+	if got := CRC(""); got != 0 {
+		t.Errorf("CRC(\"\") = 0x%08X, want 0", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	random := make([]byte, 3*blockSize+17)
+	rand.New(rand.NewSource(1)).Read(random)
+	text := bytes.Repeat([]byte("vergalid zone text "), 1000)
+
+	tests := []struct {
+		name  string
+		files []*File
+	}{
+		{"single", []*File{{Name: "a.txt", Data: []byte("hello")}}},
+		{"empty", []*File{{Name: "a.txt", Data: []byte("a")}, {Name: "empty.txt"}, {Name: "z.txt", Data: []byte("z")}}},
+		{"empty files", []*File{{Name: "empty1.txt"}, {Name: "empty2.txt"}, {Name: "empty3.txt"}}},
+		{"multi block", []*File{{Name: "random.bin", Data: random}, {Name: "text.txt", Data: text}}},
+		{"exact block", []*File{{Name: "block.bin", Data: random[:blockSize]}}},
+		{"mixed case", []*File{{Name: "Texture.PNG", Data: []byte("png")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := Write(buf, tt.files)
+			if err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			a, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if len(a.Entries) != len(tt.files) {
+				t.Fatalf("entries = %d, want %d", len(a.Entries), len(tt.files))
+			}
+			for _, f := range tt.files {
+				data, err := a.ReadFile(f.Name)
+				if err != nil {
+					t.Fatalf("read %s: %v", f.Name, err)
+				}
+				if !bytes.Equal(data, f.Data) {
+					t.Errorf("%s: got %d bytes, want %d", f.Name, len(data), len(f.Data))
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDuplicate(t *testing.T) {
+	err := Write(io.Discard, []*File{{Name: "a.txt"}, {Name: "A.TXT"}})
+	if err == nil {
+		t.Fatal("duplicate names were written")
+	}
+}
+
+// Rewriting an archive eqgzi built should give back the same bytes
+func TestRewriteExample(t *testing.T) {
+	original, err := os.ReadFile(exampleEQG)
+	if err != nil {
+		t.Fatalf("read example: %v", err)
+	}
+	a, err := NewReader(bytes.NewReader(original), int64(len(original)))
+	if err != nil {
+		t.Fatalf("open example: %v", err)
+	}
+	files, err := a.Files()
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	err = Write(buf, files)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), original) {
+		t.Errorf("rewritten archive is %d bytes and differs from the %d byte original", buf.Len(), len(original))
+	}
+}
+
+func TestCompress(t *testing.T) {
+	random := make([]byte, blockSize)
+	rand.New(rand.NewSource(2)).Read(random)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"byte", []byte{'a'}},
+		{"repeat", bytes.Repeat([]byte{0}, blockSize)},
+		{"text", bytes.Repeat([]byte("the quick brown fox "), 500)[:blockSize]},
+		{"random", random},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := compress(tt.data)
+			if len(block) < 2 || block[0] != 0x78 || block[1] != 0xda {
+				t.Fatalf("header = % x, want 78 da", block[:2])
+			}
+			r, err := zlib.NewReader(bytes.NewReader(block))
+			if err != nil {
+				t.Fatalf("zlib: %v", err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("inflate: %v", err)
+			}
+			if !bytes.Equal(data, tt.data) {
+				t.Errorf("inflated %d bytes, want %d", len(data), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"vergalid.ter", true},
+		{"texture/wood.png", true},
+		{"a..b.txt", true},
+		{"/etc/passwd", false},
+		{"\\windows\\win.ini", false},
+		{"c:\\windows\\win.ini", false},
+		{"C:/windows/win.ini", false},
+		{"..", false},
+		{"../escape.txt", false},
+		{"texture/../../escape.txt", false},
+		{"texture\\..\\..\\escape.txt", false},
+	}
+	for _, tt := range tests {
+		err := CheckName(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckName(%q) = %v, want ok %t", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package pfs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// headerSizes is the size of each EQG model header, the string table follows it
+var headerSizes = map[string]int{
+	"EQGM": 28,
+	"EQGT": 24,
+	"EQGZ": 28,
+}
+
+var referenceExtensions = map[string]bool{
+	".bmp": true,
+	".dds": true,
+	".jpg": true,
+	".png": true,
+	".mod": true,
+	".ter": true,
+	".mds": true,
+}
+
+// References returns the file names referenced by the model and zone entries of the archive
+func (a *Archive) References() ([]string, error) {
+	unique := map[string]bool{}
+	for _, e := range a.Entries {
+		ext := strings.ToLower(filepath.Ext(e.Name))
+		if ext != ".mod" && ext != ".ter" && ext != ".zon" && ext != ".mds" {
+			continue
+		}
+		data, err := a.read(e)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range stringTable(data) {
+			unique[strings.ToLower(name)] = true
+		}
+	}
+	refs := []string{}
+	for name := range unique {
+		refs = append(refs, name)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+// MissingReferences returns referenced file names that are not packed in the archive
+func (a *Archive) MissingReferences() ([]string, error) {
+	refs, err := a.References()
+	if err != nil {
+		return nil, err
+	}
+	missing := []string{}
+	for _, name := range refs {
+		if a.Entry(name) != nil {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return missing, nil
+}
+
+// stringTable returns the file names listed in the string table of an EQG model
+func stringTable(data []byte) []string {
+	if len(data) < 12 {
+		return nil
+	}
+	headerSize, ok := headerSizes[string(data[0:4])]
+	if !ok {
+		return nil
+	}
+	listLen := int(binary.LittleEndian.Uint32(data[8:12]))
+	if headerSize+listLen > len(data) {
+		return nil
+	}
+	names := []string{}
+	for _, value := range bytes.Split(data[headerSize:headerSize+listLen], []byte{0}) {
+		name := string(value)
+		if !referenceExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,141 @@
+package pfs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File is a file to pack into an archive
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Files returns every entry of the archive inflated, in archive order
+func (a *Archive) Files() ([]*File, error) {
+	files := []*File{}
+	for _, e := range a.Entries {
+		data, err := a.read(e)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", e.Name, err)
+		}
+		files = append(files, &File{Name: e.Name, Data: data})
+	}
+	return files, nil
+}
+
+// Write encodes files as a PFS archive to w. Data is stored in the order given,
+// followed by the filename table and the directory sorted by CRC
+func Write(w io.Writer, files []*File) error {
+	buf := &bytes.Buffer{}
+	// directory offset is patched in once known
+	buf.Write(make([]byte, 4))
+	buf.Write(magic[:])
+	binary.Write(buf, binary.LittleEndian, uint32(Version))
+
+	type dirEntry struct {
+		crc    uint32
+		offset uint32
+		size   uint32
+	}
+	entries := []dirEntry{}
+	names := map[string]bool{}
+	table := &bytes.Buffer{}
+	binary.Write(table, binary.LittleEndian, uint32(len(files)))
+
+	for _, f := range files {
+		name := strings.ToLower(f.Name)
+		if name == "" {
+			return fmt.Errorf("file with empty name")
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate file %s", name)
+		}
+		names[name] = true
+
+		entries = append(entries, dirEntry{crc: CRC(name), offset: uint32(buf.Len()), size: uint32(len(f.Data))})
+		err := writeBlocks(buf, f.Data)
+		if err != nil {
+			return fmt.Errorf("compress %s: %w", name, err)
+		}
+
+		binary.Write(table, binary.LittleEndian, uint32(len(name)+1))
+		table.WriteString(name)
+		table.WriteByte(0)
+	}
+
+	entries = append(entries, dirEntry{crc: filenameCRC, offset: uint32(buf.Len()), size: uint32(table.Len())})
+	err := writeBlocks(buf, table.Bytes())
+	if err != nil {
+		return fmt.Errorf("compress filename table: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].crc < entries[j].crc })
+	dirOffset := uint32(buf.Len())
+	binary.Write(buf, binary.LittleEndian, uint32(len(entries)))
+	for _, e := range entries {
+		binary.Write(buf, binary.LittleEndian, e.crc)
+		binary.Write(buf, binary.LittleEndian, e.offset)
+		binary.Write(buf, binary.LittleEndian, e.size)
+	}
+
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data[0:4], dirOffset)
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteFile writes files as a PFS archive to path, replacing it only once fully written
+func WriteFile(path string, files []*File) error {
+	w, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp: %w", err)
+	}
+	tmpPath := w.Name()
+	defer os.Remove(tmpPath)
+
+	err = Write(w, files)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	err = w.Chmod(0644)
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("chmod temp: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("close temp: %w", err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("rename temp: %w", err)
+	}
+	return nil
+}
+
+// writeBlocks compresses data into zlib blocks of at most blockSize inflated bytes, the way eqgzi does
+func writeBlocks(w *bytes.Buffer, data []byte) error {
+	for len(data) > 0 {
+		n := len(data)
+		if n > blockSize {
+			n = blockSize
+		}
+		block := compress(data[:n])
+		binary.Write(w, binary.LittleEndian, uint32(len(block)))
+		binary.Write(w, binary.LittleEndian, uint32(n))
+		w.Write(block)
+		data = data[n:]
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/download"
+)
+
+// GitHub finds releases with the GitHub release API
+type GitHub struct {
+	// URL is the API address, defaults to https://api.github.com
+	URL    string
+	Client *http.Client
+}
+
+// Release returns the release of repo tagged tag, or the newest if tag is empty.
+// Repos that only publish prereleases have no latest release, the newest tag is used instead
+func (g *GitHub) Release(ctx context.Context, repo string, tag string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", g.url(), repo)
+	if tag != "" {
+		url = fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.url(), repo, tag)
+	}
+	rel := &Release{}
+	err := g.decode(ctx, url, rel)
+	if err == nil {
+		return rel, nil
+	}
+	var statusErr statusError
+	if !errors.As(err, &statusErr) || statusErr.code != http.StatusNotFound {
+		return nil, err
+	}
+	if tag != "" {
+		return nil, fmt.Errorf("%s has no release %s", repo, tag)
+	}
+
+	releases := []*Release{}
+	err = g.decode(ctx, fmt.Sprintf("%s/repos/%s/releases", g.url(), repo), &releases)
+	if err != nil {
+		return nil, err
+	}
+	return newest(releases)
+}
+
+// Open reads an asset
+func (g *GitHub) Open(ctx context.Context, asset *Asset) (io.ReadCloser, error) {
+	return get(ctx, g.Client, asset.BrowserDownloadURL)
+}
+
+// Download saves an asset to path, resuming a partial download
+func (g *GitHub) Download(ctx context.Context, asset *Asset, path string, sum string) error {
+	return download.File(ctx, g.Client, asset.BrowserDownloadURL, path, int64(asset.Size), sum)
+}
+
+func (g *GitHub) url() string {
+	if g.URL == "" {
+		return "https://api.github.com"
+	}
+	return strings.TrimSuffix(g.URL, "/")
+}
+
+func (g *GitHub) decode(ctx context.Context, url string, v interface{}) error {
+	r, err := get(ctx, g.Client, url)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	err = json.NewDecoder(r).Decode(v)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
+}
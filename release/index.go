@@ -0,0 +1,60 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/download"
+)
+
+// Index finds releases in <URL>/<repo>/releases.json on a plain HTTP server.
+// The file is a list of releases in the GitHub API shape, asset urls may be relative to it
+type Index struct {
+	URL    string
+	Client *http.Client
+}
+
+// Release returns the release of repo tagged tag, or the newest if tag is empty
+func (x *Index) Release(ctx context.Context, repo string, tag string) (*Release, error) {
+	indexURL := fmt.Sprintf("%s/%s/releases.json", strings.TrimSuffix(x.URL, "/"), repo)
+	r, err := get(ctx, x.Client, indexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	releases := []*Release{}
+	err = json.NewDecoder(r).Decode(&releases)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", indexURL, err)
+	}
+
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", indexURL, err)
+	}
+	for _, rel := range releases {
+		for i := range rel.Assets {
+			ref, err := url.Parse(rel.Assets[i].BrowserDownloadURL)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s url: %w", rel.Assets[i].Name, err)
+			}
+			rel.Assets[i].BrowserDownloadURL = base.ResolveReference(ref).String()
+		}
+	}
+	return find(releases, repo, tag)
+}
+
+// Open reads an asset
+func (x *Index) Open(ctx context.Context, asset *Asset) (io.ReadCloser, error) {
+	return get(ctx, x.Client, asset.BrowserDownloadURL)
+}
+
+// Download saves an asset to path, resuming a partial download
+func (x *Index) Download(ctx context.Context, asset *Asset, path string, sum string) error {
+	return download.File(ctx, x.Client, asset.BrowserDownloadURL, path, int64(asset.Size), sum)
+}
@@ -0,0 +1,184 @@
+package release
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testIndex = `[
+	{"tag_name": "1.2.0", "assets": [
+		{"name": "eqgzi-1.2.0.zip", "size": 9, "browser_download_url": "1.2.0/eqgzi-1.2.0.zip"},
+		{"name": "eqgzi-1.2.0.zip.sha256", "size": 64, "browser_download_url": "/files/eqgzi-1.2.0.zip.sha256"}
+	]},
+	{"tag_name": "1.10.0", "assets": [
+		{"name": "eqgzi-1.10.0.zip", "size": 9, "browser_download_url": "https://mirror.example.com/eqgzi-1.10.0.zip", "digest": "sha256:abc123"}
+	]},
+	{"tag_name": "1.9.0", "assets": [
+		{"name": "eqgzi-1.9.0.zip", "size": 9, "browser_download_url": "1.9.0/eqgzi-1.9.0.zip"},
+		{"name": "checksums.txt", "size": 100, "browser_download_url": "1.9.0/checksums.txt"}
+	]}
+]`
+
+// newIndexServer serves testIndex as the releases of xackery/eqgzi, and the files of releases 1.2.0 and 1.9.0
+func newIndexServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	zip := "zip bytes"
+	hash := sha256.Sum256([]byte(zip))
+	sum := hex.EncodeToString(hash[:])
+	files := map[string]string{
+		"/releases/xackery/eqgzi/releases.json":         testIndex,
+		"/releases/xackery/eqgzi/1.2.0/eqgzi-1.2.0.zip": zip,
+		"/files/eqgzi-1.2.0.zip.sha256":                 sum + "\n",
+		"/releases/xackery/eqgzi/1.9.0/eqgzi-1.9.0.zip": zip,
+		"/releases/xackery/eqgzi/1.9.0/checksums.txt":   sum + "  eqgzi-1.9.0.zip\n",
+		"/releases/xackery/broken/releases.json":        "{not json",
+		"/releases/xackery/lantern/releases.json":       "[]",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIndexRelease(t *testing.T) {
+	srv := newIndexServer(t)
+	src, err := New(KindHTTP, srv.URL+"/releases/")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		repo    string
+		tag     string
+		wantTag string
+		// wantURLs are the resolved asset urls, with {srv} replaced by the server address
+		wantURLs []string
+		isError  bool
+	}{
+		{name: "newest", repo: "xackery/eqgzi", wantTag: "1.10.0", wantURLs: []string{"https://mirror.example.com/eqgzi-1.10.0.zip"}},
+		{name: "tag", repo: "xackery/eqgzi", tag: "1.2.0", wantTag: "1.2.0", wantURLs: []string{"{srv}/releases/xackery/eqgzi/1.2.0/eqgzi-1.2.0.zip", "{srv}/files/eqgzi-1.2.0.zip.sha256"}},
+		{name: "missing tag", repo: "xackery/eqgzi", tag: "2.0.0", isError: true},
+		{name: "no releases", repo: "xackery/lantern", isError: true},
+		{name: "bad index", repo: "xackery/broken", isError: true},
+		{name: "missing index", repo: "xackery/missing", isError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel, err := src.Release(context.Background(), tt.repo, tt.tag)
+			if tt.isError {
+				if err == nil {
+					t.Fatalf("found release %s", rel.TagName)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("release: %v", err)
+			}
+			if rel.TagName != tt.wantTag {
+				t.Errorf("tag = %s, want %s", rel.TagName, tt.wantTag)
+			}
+			if len(rel.Assets) != len(tt.wantURLs) {
+				t.Fatalf("assets = %d, want %d", len(rel.Assets), len(tt.wantURLs))
+			}
+			for i, asset := range rel.Assets {
+				want := strings.ReplaceAll(tt.wantURLs[i], "{srv}", srv.URL)
+				if asset.BrowserDownloadURL != want {
+					t.Errorf("%s url = %s, want %s", asset.Name, asset.BrowserDownloadURL, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexChecksum(t *testing.T) {
+	srv := newIndexServer(t)
+	src := &Index{URL: srv.URL + "/releases"}
+	hash := sha256.Sum256([]byte("zip bytes"))
+	sum := hex.EncodeToString(hash[:])
+
+	tests := []struct {
+		tag   string
+		asset string
+		want  string
+	}{
+		{"1.2.0", "eqgzi-1.2.0.zip", sum},
+		{"1.9.0", "eqgzi-1.9.0.zip", sum},
+		{"1.10.0", "eqgzi-1.10.0.zip", "abc123"},
+	}
+	for _, tt := range tests {
+		rel, err := src.Release(context.Background(), "xackery/eqgzi", tt.tag)
+		if err != nil {
+			t.Fatalf("release %s: %v", tt.tag, err)
+		}
+		asset := rel.Asset(tt.asset)
+		if asset == nil {
+			t.Fatalf("%s has no %s", tt.tag, tt.asset)
+		}
+		got, err := Checksum(context.Background(), src, rel, asset)
+		if err != nil {
+			t.Fatalf("checksum %s: %v", tt.asset, err)
+		}
+		if got != tt.want {
+			t.Errorf("checksum %s = %q, want %q", tt.asset, got, tt.want)
+		}
+	}
+}
+
+func TestIndexDownload(t *testing.T) {
+	srv := newIndexServer(t)
+	src := &Index{URL: srv.URL + "/releases"}
+	rel, err := src.Release(context.Background(), "xackery/eqgzi", "1.2.0")
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	asset := rel.Asset("eqgzi-1.2.0.zip")
+	sum, err := Checksum(context.Background(), src, rel, asset)
+	if err != nil {
+		t.Fatalf("checksum: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), asset.Name)
+	err = src.Download(context.Background(), asset, path, sum)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "zip bytes" {
+		t.Errorf("downloaded %q, %v", data, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		kind    string
+		url     string
+		isError bool
+	}{
+		{"", "", false},
+		{"github", "https://api.github.com", false},
+		{"HTTP", "https://example.com/releases", false},
+		{"http", "", true},
+		{"local", "/srv/releases", false},
+		{"local", "", true},
+		{"ftp", "ftp://example.com", true},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.kind, tt.url)
+		if (err != nil) != tt.isError {
+			t.Errorf("New(%q, %q) = %v, want error %t", tt.kind, tt.url, err, tt.isError)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/eqgzi-manager/download"
+)
+
+// Local finds releases in a folder laid out as <Dir>/<repo>/<tag>/<asset>
+type Local struct {
+	Dir string
+}
+
+// Release returns the release of repo tagged tag, or the newest if tag is empty
+func (l *Local) Release(ctx context.Context, repo string, tag string) (*Release, error) {
+	repoPath := filepath.Join(l.Dir, filepath.FromSlash(repo))
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", repo, err)
+	}
+	releases := []*Release{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		releases = append(releases, &Release{TagName: entry.Name()})
+	}
+	rel, err := find(releases, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	tagPath := filepath.Join(repoPath, rel.TagName)
+	entries, err = os.ReadDir(tagPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s %s: %w", repo, rel.TagName, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", entry.Name(), err)
+		}
+		rel.Assets = append(rel.Assets, Asset{
+			Name:               entry.Name(),
+			Size:               int(fi.Size()),
+			BrowserDownloadURL: filepath.Join(tagPath, entry.Name()),
+		})
+	}
+	return rel, nil
+}
+
+// Open reads an asset
+func (l *Local) Open(ctx context.Context, asset *Asset) (io.ReadCloser, error) {
+	return os.Open(asset.BrowserDownloadURL)
+}
+
+// Download copies an asset to path through path.part, so an interrupted copy never appears at path
+func (l *Local) Download(ctx context.Context, asset *Asset, path string, sum string) error {
+	r, err := os.Open(asset.BrowserDownloadURL)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	partPath := path + ".part"
+	w, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Base(partPath), err)
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		os.Remove(partPath)
+		return fmt.Errorf("copy %s: %w", asset.Name, err)
+	}
+	err = w.Close()
+	if err != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("close %s: %w", filepath.Base(partPath), err)
+	}
+	err = download.Verify(partPath, int64(asset.Size), sum)
+	if err != nil {
+		os.Remove(partPath)
+		return err
+	}
+	return os.Rename(partPath, path)
+}
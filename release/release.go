@@ -0,0 +1,178 @@
+// Package release finds releases of tools and the manager on GitHub, an HTTP index or a local folder
+package release
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/download"
+)
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name               string `json:"name"`
+	Size               int    `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	// Digest is the algorithm:hex sum GitHub computes for newer assets
+	Digest string `json:"digest"`
+}
+
+// Release is a tagged set of assets, in the shape of the GitHub release API
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset returns the asset called name, or nil
+func (r *Release) Asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Source finds releases of a repo, named owner/name, and fetches their assets
+type Source interface {
+	// Release returns the release of repo tagged tag, or the newest if tag is empty
+	Release(ctx context.Context, repo string, tag string) (*Release, error)
+	// Open reads an asset
+	Open(ctx context.Context, asset *Asset) (io.ReadCloser, error)
+	// Download saves an asset to path once it matches its size and sum, resuming a partial download if possible
+	Download(ctx context.Context, asset *Asset, path string, sum string) error
+}
+
+// Source kinds accepted by New
+const (
+	KindGitHub = "github"
+	KindHTTP   = "http"
+	KindLocal  = "local"
+)
+
+// New returns the source of kind, using url as the index address or folder. An empty kind is GitHub
+func New(kind string, url string) (Source, error) {
+	switch strings.ToLower(kind) {
+	case "", KindGitHub:
+		return &GitHub{URL: url}, nil
+	case KindHTTP:
+		if url == "" {
+			return nil, fmt.Errorf("http release source needs release_url")
+		}
+		return &Index{URL: url}, nil
+	case KindLocal:
+		if url == "" {
+			return nil, fmt.Errorf("local release source needs release_url")
+		}
+		return &Local{Dir: url}, nil
+	}
+	return nil, fmt.Errorf("unknown release source %s, expected github, http or local", kind)
+}
+
+// checksumFiles are release files that may list the SHA-256 of an asset, besides <asset>.sha256
+var checksumFiles = []string{"checksums.txt", "SHA256SUMS", "sha256sums.txt"}
+
+// Checksum returns the SHA-256 rel publishes for asset in a checksum file or its digest, or empty if none
+func Checksum(ctx context.Context, src Source, rel *Release, asset *Asset) (string, error) {
+	for _, name := range append([]string{asset.Name + ".sha256"}, checksumFiles...) {
+		sumAsset := rel.Asset(name)
+		if sumAsset == nil {
+			continue
+		}
+		r, err := src.Open(ctx, sumAsset)
+		if err != nil {
+			return "", fmt.Errorf("open %s: %w", name, err)
+		}
+		sum := download.ParseChecksums(r, asset.Name)
+		r.Close()
+		if sum != "" {
+			return sum, nil
+		}
+	}
+	return download.ParseDigest(asset.Digest), nil
+}
+
+// newest returns the release with the highest tag, or an error if there are none
+func newest(releases []*Release) (*Release, error) {
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no releases found")
+	}
+	rel := releases[0]
+	for _, next := range releases[1:] {
+		if CompareVersions(next.TagName, rel.TagName) > 0 {
+			rel = next
+		}
+	}
+	return rel, nil
+}
+
+// find returns the release tagged tag, or the newest if tag is empty
+func find(releases []*Release, repo string, tag string) (*Release, error) {
+	if tag == "" {
+		return newest(releases)
+	}
+	for _, rel := range releases {
+		if rel.TagName == tag {
+			return rel, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no release %s", repo, tag)
+}
+
+// get requests url and returns its body, or an error if it isn't 200 OK
+func get(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, statusError{url: url, status: resp.Status, code: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("%s returned %s", e.url, e.status)
+}
+
+// CompareVersions returns -1, 0 or 1 if a is older, equal to or newer than b.
+// Versions are dot separated numbers with an optional v prefix, missing numbers count as 0 so 3.6 equals 3.6.0.
+// Tool, manager and Blender versions all compare with it
+func CompareVersions(a string, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(strings.TrimSpace(a), "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(strings.TrimSpace(b), "v"), ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aNum, bNum := 0, 0
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum < bNum {
+			return -1
+		}
+		if aNum > bNum {
+			return 1
+		}
+	}
+	return 0
+}
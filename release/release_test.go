@@ -0,0 +1,32 @@
+package release
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{" 1.2.3 ", "v1.2.3", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.0.10", "1.0.9", 1},
+		{"0.0.6.12", "0.0.6.3", 1},
+		{"0.0.6", "0.0.6.1", -1},
+		{"2.0.0", "10.0.0", -1},
+		{"", "0.0.1", -1},
+		{"", "", 0},
+		{"3.6.2", "3.6.10", -1},
+		{"4.0", "3.6.5", 1},
+		{"2.93.1", "2.80", 1},
+		{"2.79", "2.80", -1},
+	}
+	for _, tt := range tests {
+		got := CompareVersions(tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package resource holds the files bundled into eqgzi-manager that don't need the GUI
+package resource
+
+import "strings"
+
+// Version returns the version eqgzi-manager was built as
+func Version() string {
+	return strings.TrimSpace(string(VersionText.Content()))
+}
+
+// ZoneFiles returns the files written to a new zone folder, %s is the zone name
+func ZoneFiles() map[string][]byte {
+	return map[string][]byte{
+		"convert.bat":     convertText.Content(),
+		"copy_eq.bat":     copyEQText.Content(),
+		"copy_server.bat": copyServerText.Content(),
+		"%s.blend":        baseBlend.Content(),
+		"white.png":       whitePng.Content(),
+	}
+}
@@ -0,0 +1,139 @@
+// Package selfupdate replaces the running eqgzi-manager with a newer release
+package selfupdate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/release"
+)
+
+// Repo is where eqgzi-manager releases are published
+const Repo = "xackery/eqgzi-manager"
+
+// Update is a newer release with the asset for this platform
+type Update struct {
+	Version string
+	Asset   *release.Asset
+	// Checksum is the hex SHA-256 the asset must match
+	Checksum string
+}
+
+// Updater checks for and installs new versions of the running binary
+type Updater struct {
+	// Current is the running version
+	Current string
+	// Source finds releases, defaults to GitHub
+	Source release.Source
+}
+
+// AssetName returns the release asset built for this platform, or empty if releases have none.
+// Each name must be published by .github/workflows/build_workflow.yml with a .sha256 beside it
+func AssetName() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "eqgzi-manager.exe"
+	case "linux":
+		return "eqgzi-manager-linux"
+	}
+	return ""
+}
+
+// Check returns the newest release if it is newer than Current, or nil if up to date
+func (u *Updater) Check(ctx context.Context) (*Update, error) {
+	rel, err := u.source().Release(ctx, Repo, "")
+	if err != nil {
+		return nil, fmt.Errorf("release: %w", err)
+	}
+	if release.CompareVersions(rel.TagName, u.Current) <= 0 {
+		return nil, nil
+	}
+
+	name := AssetName()
+	if name == "" {
+		return nil, fmt.Errorf("releases are not built for %s", runtime.GOOS)
+	}
+	asset := rel.Asset(name)
+	if asset == nil {
+		return nil, fmt.Errorf("release %s has no %s", rel.TagName, name)
+	}
+	sum, err := release.Checksum(ctx, u.source(), rel, asset)
+	if err != nil {
+		return nil, fmt.Errorf("checksum: %w", err)
+	}
+	if sum == "" {
+		return nil, fmt.Errorf("release %s publishes no sha256 for %s", rel.TagName, name)
+	}
+	return &Update{
+		Version:  strings.TrimPrefix(rel.TagName, "v"),
+		Asset:    asset,
+		Checksum: sum,
+	}, nil
+}
+
+// Install downloads up next to the running binary, verifies it, and swaps it in.
+// The previous binary is kept as .old until Cleanup. Returns the installed path
+func (u *Updater) Install(ctx context.Context, up *Update) (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("executable: %w", err)
+	}
+	exePath, err = filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return "", fmt.Errorf("eval executable: %w", err)
+	}
+
+	newPath := exePath + ".new"
+	err = u.source().Download(ctx, up.Asset, newPath, up.Checksum)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", up.Asset.Name, err)
+	}
+	defer os.Remove(newPath)
+	err = os.Chmod(newPath, 0755)
+	if err != nil {
+		return "", fmt.Errorf("chmod %s: %w", filepath.Base(newPath), err)
+	}
+
+	// a running binary can be renamed but not overwritten on windows
+	oldPath := exePath + ".old"
+	os.Remove(oldPath)
+	err = os.Rename(exePath, oldPath)
+	if err != nil {
+		return "", fmt.Errorf("move current binary: %w", err)
+	}
+	err = os.Rename(newPath, exePath)
+	if err != nil {
+		os.Rename(oldPath, exePath)
+		return "", fmt.Errorf("move new binary: %w", err)
+	}
+	return exePath, nil
+}
+
+// Cleanup removes the binary left behind by a previous Install
+func Cleanup() {
+	exePath, err := os.Executable()
+	if err != nil {
+		return
+	}
+	os.Remove(exePath + ".old")
+}
+
+// Restart starts path with args. The caller should exit afterwards
+func Restart(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Start()
+}
+
+func (u *Updater) source() release.Source {
+	if u.Source == nil {
+		return &release.GitHub{}
+	}
+	return u.Source
+}
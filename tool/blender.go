@@ -0,0 +1,32 @@
+package tool
+
+import (
+	"github.com/xackery/eqgzi-manager/blender"
+	"github.com/xackery/eqgzi-manager/release"
+)
+
+// blenderRanges are the Blender versions eqgzi supports, by the first eqgzi release each applies to, oldest first.
+// A release of eqgzi that changes the Blender versions its convert.py works with adds an entry
+var blenderRanges = []struct {
+	since string
+	r     blender.Range
+}{
+	// every release so far needs Blender 3 or older, Blender 4 is not supported
+	{"", blender.Range{Min: "2.80", Max: "3"}},
+}
+
+// BlenderRange returns the Blender versions version of eqgzi supports. An empty version is an unversioned install,
+// which supports the versions of the oldest release
+func BlenderRange(version string) blender.Range {
+	r := blenderRanges[0].r
+	if version == "" {
+		return r
+	}
+	for _, entry := range blenderRanges[1:] {
+		if release.CompareVersions(version, entry.since) < 0 {
+			break
+		}
+		r = entry.r
+	}
+	return r
+}
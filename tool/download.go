@@ -0,0 +1,139 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/eqgzi-manager/download"
+	"github.com/xackery/eqgzi-manager/release"
+)
+
+// Publisher is where a tool is released
+type Publisher struct {
+	// Repo is the owner/name releases are found under
+	Repo string
+	// Asset is the zip name, formatted with the release tag
+	Asset string
+}
+
+// Publishers of each tool
+var Publishers = map[string]Publisher{
+	EQGZI:   {Repo: "xackery/eqgzi", Asset: "eqgzi-%s.zip"},
+	Lantern: {Repo: "LanternEQ/LanternExtractor", Asset: "LanternExtractor-%s.zip"},
+}
+
+// Latest returns the newest released version of name
+func (m *Manager) Latest(ctx context.Context, name string) (string, error) {
+	rel, err := m.release(ctx, name, "")
+	if err != nil {
+		return "", err
+	}
+	return rel.TagName, nil
+}
+
+// CachePath returns the folder downloaded zips are kept in, beside Root
+func (m *Manager) CachePath() string {
+	return filepath.Join(filepath.Dir(m.Root), "cache")
+}
+
+// Download fetches version of name into the cache folder and installs it.
+// An empty version is the newest release. Returns the installed version
+func (m *Manager) Download(ctx context.Context, name string, version string) (string, error) {
+	rel, err := m.release(ctx, name, version)
+	if err != nil {
+		return "", err
+	}
+	zipName := fmt.Sprintf(Publishers[name].Asset, rel.TagName)
+	asset := rel.Asset(zipName)
+	if asset == nil {
+		return "", fmt.Errorf("%s %s has no %s", name, rel.TagName, zipName)
+	}
+	sum, err := release.Checksum(ctx, m.source(), rel, asset)
+	if err != nil {
+		return "", fmt.Errorf("checksum: %w", err)
+	}
+
+	err = os.MkdirAll(m.CachePath(), os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("mkdir cache: %w", err)
+	}
+	zipPath := filepath.Join(m.CachePath(), zipName)
+	_, err = os.Stat(zipPath)
+	if err == nil {
+		// an older cache may hold a truncated zip
+		err = download.Verify(zipPath, int64(asset.Size), sum)
+		if err != nil {
+			err = os.Remove(zipPath)
+			if err != nil {
+				return "", fmt.Errorf("remove cache/%s: %w", zipName, err)
+			}
+		}
+	}
+	_, err = os.Stat(zipPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("stat cache/%s: %w", zipName, err)
+		}
+		err = m.source().Download(ctx, asset, zipPath, sum)
+		if err != nil {
+			return "", fmt.Errorf("download %s: %w", zipName, err)
+		}
+	}
+
+	err = m.Install(name, rel.TagName, zipPath)
+	if err != nil {
+		return "", fmt.Errorf("install %s %s: %w", name, rel.TagName, err)
+	}
+	return rel.TagName, nil
+}
+
+// CleanCache removes downloaded zips and partial downloads, returning how many files and bytes were freed
+func (m *Manager) CleanCache() (int, int64, error) {
+	entries, err := os.ReadDir(m.CachePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("read cache: %w", err)
+	}
+	count := 0
+	size := int64(0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return count, size, fmt.Errorf("stat %s: %w", entry.Name(), err)
+		}
+		err = os.Remove(filepath.Join(m.CachePath(), entry.Name()))
+		if err != nil {
+			return count, size, fmt.Errorf("remove %s: %w", entry.Name(), err)
+		}
+		count++
+		size += fi.Size()
+	}
+	return count, size, nil
+}
+
+// release returns the release of name tagged version, or the newest if version is empty
+func (m *Manager) release(ctx context.Context, name string, version string) (*release.Release, error) {
+	pub, ok := Publishers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool %s", name)
+	}
+	rel, err := m.source().Release(ctx, pub.Repo, version)
+	if err != nil {
+		return nil, fmt.Errorf("%s release: %w", name, err)
+	}
+	return rel, nil
+}
+
+func (m *Manager) source() release.Source {
+	if m.Source == nil {
+		return &release.GitHub{}
+	}
+	return m.Source
+}
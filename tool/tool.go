@@ -0,0 +1,234 @@
+// Package tool keeps downloaded tool releases side by side under tools/<name>/<version>
+package tool
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xackery/eqgzi-manager/release"
+)
+
+// Tools managed by a Manager
+const (
+	EQGZI   = "eqgzi"
+	Lantern = "lantern"
+)
+
+// executables are the programs each tool release holds, without .exe
+var executables = map[string]string{
+	EQGZI:   "eqgzi",
+	Lantern: "LanternExtractor",
+}
+
+// Manager installs and resolves tool versions below Root
+type Manager struct {
+	Root string
+	// Source finds releases to download, defaults to GitHub
+	Source release.Source
+}
+
+// New returns a manager for the tools folder in currentPath
+func New(currentPath string) *Manager {
+	return &Manager{Root: filepath.Join(currentPath, "tools")}
+}
+
+// Versions returns the installed versions of name, newest first
+func (m *Manager) Versions(name string) ([]string, error) {
+	fi, err := os.Stat(filepath.Join(m.Root, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("stat tools/%s: %w", name, err)
+	}
+	// a native build in a flat tools folder can share the name
+	if !fi.IsDir() {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(m.Root, name))
+	if err != nil {
+		return nil, fmt.Errorf("read tools/%s: %w", name, err)
+	}
+	versions := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		versions = append(versions, entry.Name())
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return release.CompareVersions(versions[i], versions[j]) > 0
+	})
+	return versions, nil
+}
+
+// IsInstalled returns true if version of name is installed
+func (m *Manager) IsInstalled(name string, version string) bool {
+	if version == "" {
+		return false
+	}
+	fi, err := os.Stat(m.Path(name, version))
+	return err == nil && fi.IsDir()
+}
+
+// Path returns the folder version of name is installed to
+func (m *Manager) Path(name string, version string) string {
+	return filepath.Join(m.Root, name, version)
+}
+
+// Dir returns the folder to run version of name from. An empty version is the newest installed.
+// When no versions are installed side by side, the flat tools folder of older installs is used
+func (m *Manager) Dir(name string, version string) (string, error) {
+	versions, err := m.Versions(name)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		if !hasExecutable(m.Root, name) {
+			return "", fmt.Errorf("%s is not installed", name)
+		}
+		return m.Root, nil
+	}
+	if version == "" {
+		return m.Path(name, versions[0]), nil
+	}
+	if !m.IsInstalled(name, version) {
+		return "", fmt.Errorf("%s %s is not installed, installed: %s", name, version, strings.Join(versions, ", "))
+	}
+	return m.Path(name, version), nil
+}
+
+// Previous returns the newest installed version of name older than version, for rollbacks
+func (m *Manager) Previous(name string, version string) (string, error) {
+	versions, err := m.Versions(name)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		if release.CompareVersions(v, version) < 0 {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("no %s version older than %s is installed", name, version)
+}
+
+// Install extracts the release zip at zipPath as version of name, replacing it if installed
+func (m *Manager) Install(name string, version string, zipPath string) error {
+	if version == "" || strings.ContainsAny(version, `/\`) || strings.HasPrefix(version, ".") {
+		return fmt.Errorf("invalid %s version %q", name, version)
+	}
+	fi, err := os.Stat(filepath.Join(m.Root, name))
+	if err == nil && !fi.IsDir() {
+		return fmt.Errorf("tools/%s is a file of an older install, move it to install versions side by side", name)
+	}
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+
+	// extract beside the final folder so a failed extract never leaves a partial version
+	tmpPath := filepath.Join(m.Root, name, "."+version+".tmp")
+	err = os.RemoveAll(tmpPath)
+	if err != nil {
+		return fmt.Errorf("remove %s: %w", filepath.Base(tmpPath), err)
+	}
+	defer os.RemoveAll(tmpPath)
+
+	// eqgzi expects a ClientData folder beside it
+	err = os.MkdirAll(filepath.Join(tmpPath, "ClientData"), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Base(tmpPath), err)
+	}
+
+	for _, zf := range zr.File {
+		err = extractFile(zf, tmpPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	dstPath := m.Path(name, version)
+	err = os.RemoveAll(dstPath)
+	if err != nil {
+		return fmt.Errorf("remove %s %s: %w", name, version, err)
+	}
+	err = os.Rename(tmpPath, dstPath)
+	if err != nil {
+		return fmt.Errorf("move %s %s: %w", name, version, err)
+	}
+	return nil
+}
+
+// Remove deletes an installed version of name
+func (m *Manager) Remove(name string, version string) error {
+	if !m.IsInstalled(name, version) {
+		return fmt.Errorf("%s %s is not installed", name, version)
+	}
+	err := os.RemoveAll(m.Path(name, version))
+	if err != nil {
+		return fmt.Errorf("remove %s %s: %w", name, version, err)
+	}
+	return nil
+}
+
+// hasExecutable returns true if dir holds the program of name, natively built or not
+func hasExecutable(dir string, name string) bool {
+	path := filepath.Join(dir, executables[name])
+	for _, p := range []string{path + ".exe", path} {
+		_, err := os.Stat(p)
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func extractFile(zf *zip.File, dir string) error {
+	filePath := filepath.Join(dir, filepath.FromSlash(zf.Name))
+	if !strings.HasPrefix(filePath, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return fmt.Errorf("zip entry %s escapes %s", zf.Name, filepath.Base(dir))
+	}
+	// a link could point later entries outside of dir
+	if zf.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("zip entry %s is a symlink", zf.Name)
+	}
+
+	if zf.FileInfo().IsDir() {
+		err := os.MkdirAll(filePath, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("mkdir %s: %w", zf.Name, err)
+		}
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(zf.Name), err)
+	}
+	r, err := zf.Open()
+	if err != nil {
+		return fmt.Errorf("open zip %s: %w", zf.Name, err)
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, zf.Mode()|0600)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", zf.Name, err)
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return fmt.Errorf("copy %s: %w", zf.Name, err)
+	}
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("close %s: %w", zf.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package tool
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// zipEntry is a file written into a generated release zip
+type zipEntry struct {
+	name string
+	data string
+	mode os.FileMode
+}
+
+// writeZip generates a release zip of entries and returns its path
+func writeZip(t *testing.T, entries ...zipEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "release.zip")
+	w, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	defer w.Close()
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		fh := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.mode != 0 {
+			fh.SetMode(entry.mode)
+		}
+		fw, err := zw.CreateHeader(fh)
+		if err != nil {
+			t.Fatalf("create %s: %v", entry.name, err)
+		}
+		_, err = fw.Write([]byte(entry.data))
+		if err != nil {
+			t.Fatalf("write %s: %v", entry.name, err)
+		}
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return path
+}
+
+// newManager returns a manager of a temporary tools folder with versions of eqgzi installed
+func newManager(t *testing.T, versions ...string) *Manager {
+	t.Helper()
+	m := New(t.TempDir())
+	for _, version := range versions {
+		err := os.MkdirAll(filepath.Join(m.Root, EQGZI, version), os.ModePerm)
+		if err != nil {
+			t.Fatalf("mkdir %s: %v", version, err)
+		}
+	}
+	return m
+}
+
+func TestVersions(t *testing.T) {
+	m := newManager(t, "1.2.0", "1.10.0", "1.9.0", ".1.11.0.tmp")
+	err := os.WriteFile(filepath.Join(m.Root, EQGZI, "notes.txt"), nil, 0644)
+	if err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	versions, err := m.Versions(EQGZI)
+	if err != nil {
+		t.Fatalf("versions: %v", err)
+	}
+	want := []string{"1.10.0", "1.9.0", "1.2.0"}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("Versions = %v, want %v", versions, want)
+	}
+
+	versions, err = m.Versions(Lantern)
+	if err != nil || len(versions) != 0 {
+		t.Errorf("Versions(%s) = %v, %v, want none", Lantern, versions, err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		flat      string
+		version   string
+		want      string
+		wantErr   string
+	}{
+		{"newest", []string{"1.2.0", "1.10.0", "1.9.0"}, "", "", "eqgzi/1.10.0", ""},
+		{"pinned", []string{"1.2.0", "1.10.0", "1.9.0"}, "", "1.9.0", "eqgzi/1.9.0", ""},
+		{"pinned not installed", []string{"1.2.0", "1.10.0"}, "", "1.9.0", "", "eqgzi 1.9.0 is not installed, installed: 1.10.0, 1.2.0"},
+		{"flat windows", nil, "eqgzi.exe", "", ".", ""},
+		{"flat native", nil, "eqgzi", "", ".", ""},
+		{"flat ignores pin", nil, "eqgzi.exe", "1.9.0", ".", ""},
+		{"versions before flat", []string{"1.2.0"}, "eqgzi.exe", "", "eqgzi/1.2.0", ""},
+		{"not installed", nil, "", "", "", "eqgzi is not installed"},
+		{"other tool flat", nil, "LanternExtractor.exe", "", "", "eqgzi is not installed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newManager(t, tt.installed...)
+			if tt.flat != "" {
+				err := os.MkdirAll(m.Root, os.ModePerm)
+				if err != nil {
+					t.Fatalf("mkdir tools: %v", err)
+				}
+				err = os.WriteFile(filepath.Join(m.Root, tt.flat), nil, 0755)
+				if err != nil {
+					t.Fatalf("write %s: %v", tt.flat, err)
+				}
+			}
+			dir, err := m.Dir(EQGZI, tt.version)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Dir(%q) error = %v, want %q", tt.version, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Dir(%q): %v", tt.version, err)
+			}
+			want := filepath.Join(m.Root, filepath.FromSlash(tt.want))
+			if dir != want {
+				t.Errorf("Dir(%q) = %s, want %s", tt.version, dir, want)
+			}
+		})
+	}
+}
+
+func TestPrevious(t *testing.T) {
+	m := newManager(t, "1.2.0", "1.10.0", "1.9.0", "1.9.1")
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.10.0", "1.9.1"},
+		{"1.9.1", "1.9.0"},
+		{"1.9.0", "1.2.0"},
+		{"1.9.5", "1.9.1"},
+		{"2.0.0", "1.10.0"},
+		{"1.2.0", ""},
+		{"1.0.0", ""},
+	}
+	for _, tt := range tests {
+		got, err := m.Previous(EQGZI, tt.version)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Previous(%q) = %s, want an error", tt.version, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Previous(%q) = %s, %v, want %s", tt.version, got, err, tt.want)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		entries []zipEntry
+		want    []string
+		wantErr string
+	}{
+		{"install", "1.2.0", []zipEntry{{name: "eqgzi.exe", data: "exe"}, {name: "convert.py", data: "py"}, {name: "assets/"}, {name: "assets/a.txt", data: "a"}}, []string{"ClientData", "assets/a.txt", "convert.py", "eqgzi.exe"}, ""},
+		{"nested folders", "1.2.0", []zipEntry{{name: "lib/python/x.py", data: "x"}}, []string{"ClientData", "lib/python/x.py"}, ""},
+		{"parent escape", "1.2.0", []zipEntry{{name: "eqgzi.exe", data: "exe"}, {name: "../evil.txt", data: "evil"}}, nil, "escapes"},
+		{"deep escape", "1.2.0", []zipEntry{{name: "lib/../../../evil.txt", data: "evil"}}, nil, "escapes"},
+		{"sibling prefix", "1.2.0", []zipEntry{{name: "../.1.2.0.tmpx/evil.txt", data: "evil"}}, nil, "escapes"},
+		{"symlink", "1.2.0", []zipEntry{{name: "link", data: "/etc", mode: os.ModeSymlink | 0777}}, nil, "symlink"},
+		{"empty version", "", []zipEntry{{name: "eqgzi.exe"}}, nil, "invalid"},
+		{"slash version", "1.2.0/../../x", []zipEntry{{name: "eqgzi.exe"}}, nil, "invalid"},
+		{"backslash version", `..\x`, []zipEntry{{name: "eqgzi.exe"}}, nil, "invalid"},
+		{"dot version", "..", []zipEntry{{name: "eqgzi.exe"}}, nil, "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newManager(t)
+			err := m.Install(EQGZI, tt.version, writeZip(t, tt.entries...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Install error = %v, want %q", err, tt.wantErr)
+				}
+				versions, _ := m.Versions(EQGZI)
+				if len(versions) != 0 {
+					t.Errorf("failed install left versions %v", versions)
+				}
+				entries, _ := os.ReadDir(filepath.Join(m.Root, EQGZI))
+				if len(entries) != 0 {
+					t.Errorf("failed install left %d entries in tools/%s", len(entries), EQGZI)
+				}
+				_, err = os.Stat(filepath.Join(m.Root, "evil.txt"))
+				if err == nil {
+					t.Errorf("zip entry was written outside of the tools folder")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Install: %v", err)
+			}
+			if got := listFiles(t, m.Path(EQGZI, tt.version)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("installed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallReplaces(t *testing.T) {
+	m := newManager(t)
+	err := m.Install(EQGZI, "1.2.0", writeZip(t, zipEntry{name: "eqgzi.exe", data: "old"}, zipEntry{name: "old.txt"}))
+	if err != nil {
+		t.Fatalf("install old: %v", err)
+	}
+	err = m.Install(EQGZI, "1.2.0", writeZip(t, zipEntry{name: "eqgzi.exe", data: "new"}))
+	if err != nil {
+		t.Fatalf("install new: %v", err)
+	}
+	want := []string{"ClientData", "eqgzi.exe"}
+	if got := listFiles(t, m.Path(EQGZI, "1.2.0")); !reflect.DeepEqual(got, want) {
+		t.Errorf("installed %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(m.Path(EQGZI, "1.2.0"), "eqgzi.exe"))
+	if err != nil || string(data) != "new" {
+		t.Errorf("eqgzi.exe = %q, %v, want new", data, err)
+	}
+
+	// a failed reinstall keeps the working version
+	err = m.Install(EQGZI, "1.2.0", writeZip(t, zipEntry{name: "../evil.txt"}))
+	if err == nil {
+		t.Fatal("escaping zip was installed")
+	}
+	if !m.IsInstalled(EQGZI, "1.2.0") {
+		t.Error("failed reinstall removed the installed version")
+	}
+}
+
+func TestInstallFlatFile(t *testing.T) {
+	m := newManager(t)
+	err := os.MkdirAll(m.Root, os.ModePerm)
+	if err != nil {
+		t.Fatalf("mkdir tools: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(m.Root, EQGZI), nil, 0755)
+	if err != nil {
+		t.Fatalf("write flat eqgzi: %v", err)
+	}
+	err = m.Install(EQGZI, "1.2.0", writeZip(t, zipEntry{name: "eqgzi"}))
+	if err == nil || !strings.Contains(err.Error(), "is a file") {
+		t.Errorf("Install over a flat tools/%s = %v, want an error", EQGZI, err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := newManager(t, "1.2.0", "1.9.0")
+	err := m.Remove(EQGZI, "1.2.0")
+	if err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	versions, _ := m.Versions(EQGZI)
+	if want := []string{"1.9.0"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("Versions after remove = %v, want %v", versions, want)
+	}
+	err = m.Remove(EQGZI, "1.2.0")
+	if err == nil {
+		t.Error("removing a missing version succeeded")
+	}
+}
+
+// listFiles returns the files and empty folders under dir, slash separated and sorted
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files := []string{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if fi.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil || len(entries) > 0 {
+				return err
+			}
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk %s: %v", dir, err)
+	}
+	return files
+}
+
+func TestBlenderRange(t *testing.T) {
+	tests := []struct {
+		version string
+		blender string
+		want    bool
+	}{
+		{"", "3.6.2", true},
+		{"", "4.0.2", false},
+		{"0.0.6", "2.93.18", true},
+		{"0.0.6", "2.79", false},
+		{"0.0.6", "4.1", false},
+	}
+	for _, tt := range tests {
+		r := BlenderRange(tt.version)
+		if got := r.Contains(tt.blender); got != tt.want {
+			t.Errorf("BlenderRange(%q) %s contains %s = %t, want %t", tt.version, r, tt.blender, got, tt.want)
+		}
+	}
+}
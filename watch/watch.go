@@ -0,0 +1,141 @@
+// Package watch reports changes to the source files of a zone
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long a zone must stay unchanged before a change is reported
+const DefaultDebounce = 2 * time.Second
+
+// textureExtensions are the image files a zone's textures are saved as
+var textureExtensions = []string{".png", ".jpg", ".jpeg", ".dds", ".bmp", ".tga"}
+
+// Watcher watches a zone folder for changes to <zone>.blend, textures and *.txt animation definitions
+type Watcher struct {
+	// Dir is the zone folder
+	Dir  string
+	Zone string
+	// Debounce is how long the zone must stay unchanged before OnChange is called, 0 uses DefaultDebounce
+	Debounce time.Duration
+	// OnChange is called with the changed files, relative to Dir, once changes settle.
+	// Changes made while it runs are reported after it returns. If it returns false,
+	// the changes are reported again after another debounce
+	OnChange func(paths []string) bool
+}
+
+// IsSource returns true if path, relative to the zone folder, is a file that changes the build of zone
+func IsSource(zone string, path string) bool {
+	path = filepath.ToSlash(path)
+	dir, name := filepath.Split(path)
+	dir = strings.TrimSuffix(dir, "/")
+	if dir != "" && dir != "texture" {
+		return false
+	}
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if dir == "" && strings.EqualFold(name, zone+".blend") {
+		return true
+	}
+	if ext == ".txt" {
+		return true
+	}
+	for _, textureExt := range textureExtensions {
+		if ext == textureExt {
+			return true
+		}
+	}
+	return false
+}
+
+// Run watches until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("new watcher: %w", err)
+	}
+	defer fw.Close()
+
+	err = fw.Add(w.Dir)
+	if err != nil {
+		return fmt.Errorf("watch %s: %w", w.Zone, err)
+	}
+	textureDir := filepath.Join(w.Dir, "texture")
+	isTextureWatched := false
+	addTextureDir := func() {
+		if isTextureWatched {
+			return
+		}
+		fi, err := os.Stat(textureDir)
+		if err != nil || !fi.IsDir() {
+			return
+		}
+		if fw.Add(textureDir) == nil {
+			isTextureWatched = true
+		}
+	}
+	addTextureDir()
+
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	pending := map[string]bool{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watch %s: %w", w.Zone, err)
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			rel, err := filepath.Rel(w.Dir, event.Name)
+			if err != nil {
+				continue
+			}
+			if filepath.ToSlash(rel) == "texture" {
+				isTextureWatched = false
+				addTextureDir()
+				continue
+			}
+			if event.Op == fsnotify.Chmod || !IsSource(w.Zone, rel) {
+				continue
+			}
+			pending[filepath.ToSlash(rel)] = true
+			timer.Reset(debounce)
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			paths := []string{}
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			if !w.OnChange(paths) {
+				timer.Reset(debounce)
+				continue
+			}
+			pending = map[string]bool{}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package watch
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSource(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"testzone.blend", true},
+		{"TestZone.Blend", true},
+		{"other.blend", false},
+		{"testzone.blend1", false},
+		{"testzone.blend@", false},
+		{"wood.png", true},
+		{"wood.PNG", true},
+		{"wood.dds", true},
+		{"wood.tga", true},
+		{"animated_sign1.txt", true},
+		{"texture/x.png", true},
+		{"texture/x.jpeg", true},
+		{"texture/testzone.blend", false},
+		{filepath.Join("texture", "x.bmp"), true},
+		{"texture/sub/x.png", false},
+		{"out/x.eqg", false},
+		{"out/x.png", false},
+		{"map/testzone.map", false},
+		{"cache/testzone.gltf", false},
+		{"convert.log", false},
+		{"testzone.eqg", false},
+		{".wood.png", false},
+		{".testzone.blend", false},
+		{"texture/.x.png", false},
+		{".build", false},
+		{"wood.png~", false},
+		{"notes.txt~", false},
+	}
+	for _, tt := range tests {
+		got := IsSource("testzone", tt.path)
+		if got != tt.want {
+			t.Errorf("IsSource(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
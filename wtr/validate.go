@@ -0,0 +1,73 @@
+package wtr
+
+import (
+	"fmt"
+	"math"
+)
+
+// Issue is a problem found with a region
+type Issue struct {
+	// Region is the index of the region
+	Region int
+	// IsError is true when the server will not use the region as intended
+	IsError bool
+	Message string
+}
+
+// String returns the issue prefixed with its region
+func (i Issue) String() string {
+	level := "warning"
+	if i.IsError {
+		level = "error"
+	}
+	return fmt.Sprintf("region %d %s: %s", i.Region, level, i.Message)
+}
+
+// Bounds returns the axis aligned box holding the region
+func (r *Region) Bounds() (min Vec3, max Vec3) {
+	half := Vec3{
+		X: float32(math.Abs(float64(r.Extents.X * r.Scale.X))),
+		Y: float32(math.Abs(float64(r.Extents.Y * r.Scale.Y))),
+		Z: float32(math.Abs(float64(r.Extents.Z * r.Scale.Z))),
+	}
+	if r.Rotation != (Vec3{}) {
+		// any rotation fits inside the sphere around the box
+		radius := float32(math.Sqrt(float64(half.X*half.X + half.Y*half.Y + half.Z*half.Z)))
+		half = Vec3{radius, radius, radius}
+	}
+	min = Vec3{r.Position.X - half.X, r.Position.Y - half.Y, r.Position.Z - half.Z}
+	max = Vec3{r.Position.X + half.X, r.Position.Y + half.Y, r.Position.Z + half.Z}
+	return min, max
+}
+
+// Validate checks every region against the zone bounding box zoneMin to zoneMax.
+// The box is in water file space; azone maps store x and y swapped
+func (f *File) Validate(zoneMin Vec3, zoneMax Vec3) []Issue {
+	issues := []Issue{}
+	for i, r := range f.Regions {
+		if _, ok := regionTypeNames[r.Type]; !ok || r.Type == Unsupported {
+			issues = append(issues, Issue{Region: i, IsError: true, Message: fmt.Sprintf("type %s is not supported by the server", r.Type)})
+		}
+		if r.Type == Untagged {
+			issues = append(issues, Issue{Region: i, Message: "region is untagged and has no effect"})
+		}
+		if r.Extents.X*r.Scale.X == 0 || r.Extents.Y*r.Scale.Y == 0 || r.Extents.Z*r.Scale.Z == 0 {
+			issues = append(issues, Issue{Region: i, IsError: true, Message: "region has no volume"})
+			continue
+		}
+
+		min, max := r.Bounds()
+		if max.X < zoneMin.X || min.X > zoneMax.X ||
+			max.Y < zoneMin.Y || min.Y > zoneMax.Y ||
+			max.Z < zoneMin.Z || min.Z > zoneMax.Z {
+			issues = append(issues, Issue{Region: i, IsError: true, Message: "region is outside the zone"})
+			continue
+		}
+		if min.X < zoneMin.X || max.X > zoneMax.X ||
+			min.Y < zoneMin.Y || max.Y > zoneMax.Y ||
+			min.Z < zoneMin.Z || max.Z > zoneMax.Z {
+			issues = append(issues, Issue{Region: i, Message: "region extends past the zone"})
+		}
+	}
+	return issues
+}
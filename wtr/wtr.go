@@ -0,0 +1,180 @@
+// Package wtr reads and writes EQEMUWATER region files produced by awater
+package wtr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Version is the only region file version supported
+const Version = 2
+
+var magic = []byte("EQEMUWATER")
+
+// RegionType is the effect a region has on entities inside it
+type RegionType int32
+
+// Region types understood by the EQEmu server
+const (
+	Unsupported    RegionType = -2
+	Untagged       RegionType = -1
+	Normal         RegionType = 0
+	Water          RegionType = 1
+	Lava           RegionType = 2
+	ZoneLine       RegionType = 3
+	PVP            RegionType = 4
+	Slime          RegionType = 5
+	Ice            RegionType = 6
+	VWater         RegionType = 7
+	GenericArea    RegionType = 8
+	PreferPathing  RegionType = 9
+	DisableNavMesh RegionType = 10
+)
+
+var regionTypeNames = map[RegionType]string{
+	Unsupported:    "Unsupported",
+	Untagged:       "Untagged",
+	Normal:         "Normal",
+	Water:          "Water",
+	Lava:           "Lava",
+	ZoneLine:       "Zone Line",
+	PVP:            "PvP",
+	Slime:          "Slime",
+	Ice:            "Ice",
+	VWater:         "V Water",
+	GenericArea:    "Generic Area",
+	PreferPathing:  "Prefer Pathing",
+	DisableNavMesh: "Disable Nav Mesh",
+}
+
+// RegionTypes returns every known region type in order
+func RegionTypes() []RegionType {
+	types := []RegionType{}
+	for t := Unsupported; t <= DisableNavMesh; t++ {
+		types = append(types, t)
+	}
+	return types
+}
+
+// ParseRegionType returns the region type named name
+func ParseRegionType(name string) (RegionType, error) {
+	for t, typeName := range regionTypeNames {
+		if typeName == name {
+			return t, nil
+		}
+	}
+	return Unsupported, fmt.Errorf("unknown region type %s", name)
+}
+
+// String returns the display name of the region type
+func (t RegionType) String() string {
+	name, ok := regionTypeNames[t]
+	if !ok {
+		return fmt.Sprintf("Unknown(%d)", int32(t))
+	}
+	return name
+}
+
+// Vec3 is a point or size in zone space
+type Vec3 struct {
+	X, Y, Z float32
+}
+
+// Region is an oriented box. The box spans -Extents to Extents, scaled,
+// rotated then moved to Position
+type Region struct {
+	Type     RegionType
+	Position Vec3
+	Rotation Vec3
+	Scale    Vec3
+	Extents  Vec3
+}
+
+// File is a decoded region file
+type File struct {
+	Version uint32
+	Regions []*Region
+}
+
+// Open decodes the region file at path
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+// Decode reads a region file from r
+func Decode(r io.Reader) (*File, error) {
+	header := make([]byte, len(magic))
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("read magic: %w", err)
+	}
+	if !bytes.Equal(header, magic) {
+		return nil, fmt.Errorf("not a water file, magic %q", header)
+	}
+
+	f := &File{}
+	err = binary.Read(r, binary.LittleEndian, &f.Version)
+	if err != nil {
+		return nil, fmt.Errorf("read version: %w", err)
+	}
+	if f.Version != Version {
+		return nil, fmt.Errorf("unsupported water version %d", f.Version)
+	}
+
+	count := uint32(0)
+	err = binary.Read(r, binary.LittleEndian, &count)
+	if err != nil {
+		return nil, fmt.Errorf("read region count: %w", err)
+	}
+	for i := uint32(0); i < count; i++ {
+		region := &Region{}
+		err = binary.Read(r, binary.LittleEndian, region)
+		if err != nil {
+			return nil, fmt.Errorf("read region %d of %d: %w", i, count, err)
+		}
+		f.Regions = append(f.Regions, region)
+	}
+	return f, nil
+}
+
+// Encode writes f to w
+func (f *File) Encode(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	buf.Write(magic)
+	binary.Write(buf, binary.LittleEndian, uint32(Version))
+	binary.Write(buf, binary.LittleEndian, uint32(len(f.Regions)))
+	for _, region := range f.Regions {
+		binary.Write(buf, binary.LittleEndian, region)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteFile writes f to path, replacing it only once fully written
+func (f *File) WriteFile(path string) error {
+	buf := &bytes.Buffer{}
+	err := f.Encode(buf)
+	if err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	err = os.WriteFile(tmpPath, buf.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("write temp: %w", err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename temp: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package wtr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const exampleWtr = "../example/zones/vergalid/map/vergalid.wtr"
+
+func TestEncodeExample(t *testing.T) {
+	data, err := os.ReadFile(exampleWtr)
+	if err != nil {
+		t.Fatalf("read example: %v", err)
+	}
+	f, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if f.Version != Version || len(f.Regions) != 4 {
+		t.Fatalf("version %d with %d regions, want %d with 4", f.Version, len(f.Regions), Version)
+	}
+	buf := &bytes.Buffer{}
+	err = f.Encode(buf)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("encoded %d bytes differ from the %d byte example", buf.Len(), len(data))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		regions []*Region
+	}{
+		{"empty", nil},
+		{"water", []*Region{{Type: Water, Position: Vec3{1, 2, 3}, Scale: Vec3{1, 1, 1}, Extents: Vec3{5, 5, 5}}}},
+		{"every type", func() []*Region {
+			regions := []*Region{}
+			for _, regionType := range RegionTypes() {
+				regions = append(regions, &Region{Type: regionType, Rotation: Vec3{0, 0, 1.5}, Scale: Vec3{2, 2, 2}, Extents: Vec3{1, 2, 3}})
+			}
+			return regions
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "zone.wtr")
+			err := (&File{Version: Version, Regions: tt.regions}).WriteFile(path)
+			if err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			f, err := Open(path)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if len(f.Regions) != len(tt.regions) {
+				t.Fatalf("regions = %d, want %d", len(f.Regions), len(tt.regions))
+			}
+			for i, region := range f.Regions {
+				if *region != *tt.regions[i] {
+					t.Errorf("region %d = %+v, want %+v", i, *region, *tt.regions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	valid := &bytes.Buffer{}
+	(&File{Regions: []*Region{{Type: Water}}}).Encode(valid)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", append([]byte("EQEMUWATEX"), valid.Bytes()[10:]...)},
+		{"version 1", append(append([]byte("EQEMUWATER"), 1, 0, 0, 0), valid.Bytes()[14:]...)},
+		{"truncated", valid.Bytes()[:valid.Len()-4]},
+	}
+	for _, tt := range tests {
+		_, err := Decode(bytes.NewReader(tt.data))
+		if err == nil {
+			t.Errorf("%s: decoded without error", tt.name)
+		}
+	}
+}
+
+func TestParseRegionType(t *testing.T) {
+	for _, regionType := range RegionTypes() {
+		got, err := ParseRegionType(regionType.String())
+		if err != nil || got != regionType {
+			t.Errorf("ParseRegionType(%q) = %v, %v, want %v", regionType.String(), got, err, regionType)
+		}
+	}
+	_, err := ParseRegionType("Quicksand")
+	if err == nil {
+		t.Error("ParseRegionType(\"Quicksand\") succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	zoneMin := Vec3{-100, -100, -100}
+	zoneMax := Vec3{100, 100, 100}
+	unit := Vec3{1, 1, 1}
+
+	tests := []struct {
+		name    string
+		region  Region
+		issues  int
+		isError bool
+	}{
+		{"inside", Region{Type: Water, Scale: unit, Extents: Vec3{10, 10, 10}}, 0, false},
+		{"untagged", Region{Type: Untagged, Scale: unit, Extents: Vec3{10, 10, 10}}, 1, false},
+		{"unsupported", Region{Type: Unsupported, Scale: unit, Extents: Vec3{10, 10, 10}}, 1, true},
+		{"unknown type", Region{Type: 42, Scale: unit, Extents: Vec3{10, 10, 10}}, 1, true},
+		{"no volume", Region{Type: Water, Scale: Vec3{1, 0, 1}, Extents: Vec3{10, 10, 10}}, 1, true},
+		{"outside", Region{Type: Water, Position: Vec3{500, 0, 0}, Scale: unit, Extents: Vec3{10, 10, 10}}, 1, true},
+		{"past the edge", Region{Type: Water, Position: Vec3{95, 0, 0}, Scale: unit, Extents: Vec3{10, 10, 10}}, 1, false},
+		{"rotated past the edge", Region{Type: Lava, Position: Vec3{85, 0, 0}, Rotation: Vec3{0, 0, 1}, Scale: unit, Extents: Vec3{10, 10, 10}}, 1, false},
+	}
+	for _, tt := range tests {
+		region := tt.region
+		issues := (&File{Version: Version, Regions: []*Region{&region}}).Validate(zoneMin, zoneMax)
+		if len(issues) != tt.issues {
+			t.Errorf("%s: issues = %v, want %d", tt.name, issues, tt.issues)
+			continue
+		}
+		if len(issues) > 0 && issues[0].IsError != tt.isError {
+			t.Errorf("%s: %s, want error %t", tt.name, issues[0], tt.isError)
+		}
+	}
+}
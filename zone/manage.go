@@ -0,0 +1,244 @@
+package zone
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trashTimeFormat suffixes zones moved to the trash folder, so a zone can be deleted more than once
+const trashTimeFormat = "20060102-150405"
+
+// Clone copies the zone src to a new zone dst, renaming files named after src.
+// The build cache and history in .build aren't copied
+func Clone(currentPath string, src string, dst string) error {
+	srcPath := zonePath(currentPath, src)
+	dstPath, err := newZonePath(currentPath, dst)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(srcPath)
+	if err != nil {
+		return fmt.Errorf("zone %s: %w", src, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("zone %s is not a folder", src)
+	}
+
+	err = filepath.WalkDir(srcPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcPath, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && rel == ".build" {
+			return fs.SkipDir
+		}
+		dstFile := filepath.Join(dstPath, filepath.Dir(rel), derivedName(d.Name(), src, dst))
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dstPath, rel), os.ModePerm)
+		}
+		err = copyFile(path, dstFile)
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", rel, err)
+		}
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(dstPath)
+		return fmt.Errorf("clone %s: %w", src, err)
+	}
+	return nil
+}
+
+// Rename moves the zone oldName to newName, renaming files named after oldName.
+// Files are renamed before the folder is moved, and renamed back if any of it fails
+func Rename(currentPath string, oldName string, newName string) error {
+	oldPath := zonePath(currentPath, oldName)
+	newPath, err := newZonePath(currentPath, newName)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(oldPath)
+	if err != nil {
+		return fmt.Errorf("zone %s: %w", oldName, err)
+	}
+
+	paths := []string{}
+	err = filepath.WalkDir(oldPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && derivedName(d.Name(), oldName, newName) != d.Name() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk %s: %w", oldName, err)
+	}
+
+	renamed := [][2]string{}
+	rollback := func() {
+		for i := len(renamed) - 1; i >= 0; i-- {
+			os.Rename(renamed[i][1], renamed[i][0])
+		}
+	}
+	for _, path := range paths {
+		dst := filepath.Join(filepath.Dir(path), derivedName(filepath.Base(path), oldName, newName))
+		_, err = os.Lstat(dst)
+		if err == nil {
+			rollback()
+			return fmt.Errorf("rename %s: %s already exists", filepath.Base(path), filepath.Base(dst))
+		}
+		err = os.Rename(path, dst)
+		if err != nil {
+			rollback()
+			return fmt.Errorf("rename %s: %w", filepath.Base(path), err)
+		}
+		renamed = append(renamed, [2]string{path, dst})
+	}
+	err = os.Rename(oldPath, newPath)
+	if err != nil {
+		rollback()
+		return fmt.Errorf("rename %s: %w", oldName, err)
+	}
+	return nil
+}
+
+// Delete moves the zone name into currentPath/trash and returns the name it was given there
+func Delete(currentPath string, name string) (string, error) {
+	srcPath := zonePath(currentPath, name)
+	_, err := os.Stat(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("zone %s: %w", name, err)
+	}
+	trashPath := filepath.Join(currentPath, "trash")
+	err = os.MkdirAll(trashPath, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("mkdir trash: %w", err)
+	}
+	trashName := fmt.Sprintf("%s-%s", name, time.Now().Format(trashTimeFormat))
+	err = os.Rename(srcPath, filepath.Join(trashPath, trashName))
+	if err != nil {
+		return "", fmt.Errorf("move %s to trash: %w", name, err)
+	}
+	return trashName, nil
+}
+
+// Trash returns the deleted zones in currentPath/trash, oldest first
+func Trash(currentPath string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(currentPath, "trash"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read trash: %w", err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return trashTime(names[i]) < trashTime(names[j])
+	})
+	return names, nil
+}
+
+// Restore moves trashName out of the trash folder back to zones, named as before it was deleted
+func Restore(currentPath string, trashName string) (string, error) {
+	name := trashName
+	suffix := trashTime(trashName)
+	if suffix != "" {
+		name = strings.TrimSuffix(trashName, "-"+suffix)
+	}
+	srcPath := filepath.Join(currentPath, "trash", trashName)
+	_, err := os.Stat(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("trash %s: %w", trashName, err)
+	}
+	dstPath, err := newZonePath(currentPath, name)
+	if err != nil {
+		return "", err
+	}
+	err = os.Rename(srcPath, dstPath)
+	if err != nil {
+		return "", fmt.Errorf("restore %s: %w", trashName, err)
+	}
+	return name, nil
+}
+
+// trashTime returns the deletion time suffix of a trash name, or empty
+func trashTime(trashName string) string {
+	if len(trashName) <= len(trashTimeFormat)+1 {
+		return ""
+	}
+	suffix := trashName[len(trashName)-len(trashTimeFormat):]
+	_, err := time.Parse(trashTimeFormat, suffix)
+	if err != nil {
+		return ""
+	}
+	return suffix
+}
+
+// derivedName returns fileName with the zone name oldName replaced by newName,
+// if it is named after the zone like oldName.blend or oldName_lights.txt
+func derivedName(fileName string, oldName string, newName string) string {
+	lower := strings.ToLower(fileName)
+	if lower == oldName {
+		return newName
+	}
+	if strings.HasPrefix(lower, oldName+".") || strings.HasPrefix(lower, oldName+"_") {
+		return newName + fileName[len(oldName):]
+	}
+	return fileName
+}
+
+func zonePath(currentPath string, name string) string {
+	return filepath.Join(currentPath, "zones", name)
+}
+
+// newZonePath returns the folder for a new zone called name, or an error if name is invalid or taken
+func newZonePath(currentPath string, name string) (string, error) {
+	err := ValidateName(name)
+	if err != nil {
+		return "", err
+	}
+	path := zonePath(currentPath, name)
+	_, err = os.Stat(path)
+	if err == nil {
+		return "", fmt.Errorf("zone %s already exists", name)
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("stat zone %s: %w", name, err)
+	}
+	return path, nil
+}
+
+func copyFile(src string, dst string) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,108 @@
+package zone
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// writeZone creates a zone folder holding files, and returns the workspace path
+func writeZone(t *testing.T, name string, files ...string) string {
+	t.Helper()
+	currentPath := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(zonePath(currentPath, name), file)
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(file), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return currentPath
+}
+
+// zoneFiles returns the files of a zone, relative to its folder
+func zoneFiles(t *testing.T, currentPath string, name string) []string {
+	t.Helper()
+	root := zonePath(currentPath, name)
+	files := []string{}
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func equalFiles(got []string, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestClone(t *testing.T) {
+	currentPath := writeZone(t, "oldzone", "oldzone.blend", "oldzone_lights.txt", "texture/oldzone.png", "notes.txt", ".build/steps.json")
+	err := Clone(currentPath, "oldzone", "newzone")
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	want := []string{"newzone.blend", "newzone_lights.txt", "notes.txt", "texture/newzone.png"}
+	got := zoneFiles(t, currentPath, "newzone")
+	if !equalFiles(got, want) {
+		t.Errorf("cloned files = %v, want %v", got, want)
+	}
+}
+
+func TestRename(t *testing.T) {
+	currentPath := writeZone(t, "oldzone", "oldzone.blend", "out/oldzone.eqg", "notes.txt")
+	err := Rename(currentPath, "oldzone", "newzone")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	want := []string{"newzone.blend", "notes.txt", "out/newzone.eqg"}
+	got := zoneFiles(t, currentPath, "newzone")
+	if !equalFiles(got, want) {
+		t.Errorf("renamed files = %v, want %v", got, want)
+	}
+	_, err = os.Stat(zonePath(currentPath, "oldzone"))
+	if !os.IsNotExist(err) {
+		t.Errorf("oldzone still exists: %v", err)
+	}
+}
+
+func TestRenameRollback(t *testing.T) {
+	// out/newzone.eqg is in the way of renaming out/oldzone.eqg, after oldzone.blend was renamed
+	files := []string{"oldzone.blend", "out/newzone.eqg", "out/oldzone.eqg"}
+	currentPath := writeZone(t, "oldzone", files...)
+	err := Rename(currentPath, "oldzone", "newzone")
+	if err == nil {
+		t.Fatal("rename over an existing file succeeded")
+	}
+	got := zoneFiles(t, currentPath, "oldzone")
+	if !equalFiles(got, files) {
+		t.Errorf("files after a failed rename = %v, want %v", got, files)
+	}
+	_, err = os.Stat(zonePath(currentPath, "newzone"))
+	if !os.IsNotExist(err) {
+		t.Errorf("newzone exists after a failed rename: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package zone
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jbsmith7741/toml"
+)
+
+// ManifestName is the file in a zone folder that describes how it builds
+const ManifestName = "zone.toml"
+
+// Manifest is the zone.toml of a zone folder
+type Manifest struct {
+	LongName     string   `toml:"long_name" desc:"Name shown in game, e.g. Greater Faydark"`
+	ZoneID       int      `toml:"zone_id" desc:"zoneidnumber in the server's zone table, 0 if unassigned"`
+	BlenderPath  string   `toml:"blender_path" desc:"Blender to build with instead of blender_path in eqgzi-manager.conf"`
+	EQGZIVersion string   `toml:"eqgzi_version" desc:"eqgzi version to build with unless eqgzi-manager.conf pins this zone"`
+	Skip         []string `toml:"skip" desc:"Build steps to skip, e.g. awater"`
+	Order        []string `toml:"order" desc:"Build step order, unlisted steps run after"`
+	ExtraOutputs []string `toml:"extra_outputs" desc:"Files copied to out/ after converting, and shipped with the .eqg"`
+	// Steps and Copies are kept last, toml writes tables after plain keys
+	Steps  []ManifestStep `toml:"steps" desc:"Extra build steps"`
+	Copies []Copy         `toml:"copy" desc:"Extra files copied when the zone is copied"`
+}
+
+// ManifestStep is an extra build step, {zone} in Command and Args is replaced with the zone name
+type ManifestStep struct {
+	Name    string   `toml:"name"`
+	Before  string   `toml:"before" desc:"Step this runs before, empty runs last"`
+	Command string   `toml:"command" desc:"Executable, relative to the zone folder if it has a slash"`
+	Args    []string `toml:"args"`
+	Dir     string   `toml:"dir" desc:"Working folder, relative to the zone folder"`
+	Inputs  []string `toml:"inputs"`
+	Outputs []string `toml:"outputs"`
+}
+
+// Copy copies files matching a glob in the zone folder to a target
+type Copy struct {
+	Files string `toml:"files" desc:"Glob relative to the zone folder, e.g. map/*.path"`
+	// Target is eq or server, optionally followed by a subfolder, or an absolute folder
+	Target string `toml:"target" desc:"eq, server, eq/<folder>, server/<folder> or an absolute folder"`
+}
+
+// LoadManifest reads the zone.toml in dir. A missing file returns an empty manifest
+func LoadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", ManifestName, err)
+	}
+	_, err = toml.Decode(string(data), m)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", ManifestName, err)
+	}
+	err = m.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	return m, nil
+}
+
+// Save writes m to the zone.toml in dir
+func (m *Manifest) Save(dir string) error {
+	err := m.Validate()
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	err = toml.NewEncoder(buf).Encode(m)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", ManifestName, err)
+	}
+	err = os.WriteFile(filepath.Join(dir, ManifestName), buf.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", ManifestName, err)
+	}
+	return nil
+}
+
+// Validate returns an error if a step or copy is missing a required value
+func (m *Manifest) Validate() error {
+	if m.ZoneID < 0 {
+		return fmt.Errorf("zone_id %d cannot be negative", m.ZoneID)
+	}
+	for i, step := range m.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d has no name", i+1)
+		}
+		if step.Command == "" {
+			return fmt.Errorf("step %s has no command", step.Name)
+		}
+	}
+	for i, c := range m.Copies {
+		if c.Files == "" {
+			return fmt.Errorf("copy %d has no files", i+1)
+		}
+		if c.Target == "" {
+			return fmt.Errorf("copy %s has no target", c.Files)
+		}
+	}
+	for _, path := range m.ExtraOutputs {
+		if filepath.IsAbs(path) || strings.HasPrefix(filepath.ToSlash(filepath.Clean(path)), "../") {
+			return fmt.Errorf("extra output %s is outside of the zone folder", path)
+		}
+	}
+	return nil
+}
+
+// IsSkipped returns true if the step named name is skipped
+func (m *Manifest) IsSkipped(name string) bool {
+	for _, skip := range m.Skip {
+		if skip == name {
+			return true
+		}
+	}
+	return false
+}
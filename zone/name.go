@@ -0,0 +1,259 @@
+package zone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xackery/eqgzi-manager/eqemu"
+)
+
+// MaxNameLength is the longest short name that fits the client's 32 byte zone name buffers
+const MaxNameLength = 31
+
+// ValidateName returns an error if name breaks the client's short name rules:
+// a lowercase ascii letter followed by lowercase letters and digits.
+// Underscores are refused since _obj, _chr and _lit name a zone's extra archives
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("zone cannot be empty")
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("zone %s is %d characters, the client allows %d", name, len(name), MaxNameLength)
+	}
+	if name[0] < 'a' || name[0] > 'z' {
+		return fmt.Errorf("zone %s must start with a lowercase letter", name)
+	}
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			continue
+		}
+		switch r {
+		case ' ':
+			return fmt.Errorf("zone %s shouldn't have a space", name)
+		case '.':
+			return fmt.Errorf("zone %s shouldn't have a period", name)
+		case '/', '\\':
+			return fmt.Errorf("zone %s shouldn't have a slash", name)
+		case '_':
+			return fmt.Errorf("zone %s shouldn't have an underscore, it marks _obj and _chr archives", name)
+		}
+		return fmt.Errorf("zone %s has %q, only lowercase letters and digits are allowed", name, r)
+	}
+	return nil
+}
+
+// CheckName validates name and returns problems that don't stop it from being used.
+// If serverPath is set, the server's zone table is checked for name and a free zone id
+func CheckName(ctx context.Context, name string, serverPath string) ([]string, error) {
+	err := ValidateName(name)
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+	longName, ok := StockZones[name]
+	if ok {
+		warnings = append(warnings, fmt.Sprintf("%s is the stock zone %s, copying it to EverQuest replaces the original", name, longName))
+	}
+	if serverPath == "" {
+		return warnings, nil
+	}
+	status, err := eqemu.CheckZone(ctx, serverPath, name)
+	if err != nil {
+		return append(warnings, fmt.Sprintf("skipped server zone table check: %s", err)), nil
+	}
+	return append(warnings, status.Warnings(name)...), nil
+}
+
+// StockZones maps the short names of zones shipped with EverQuest to their long names
+var StockZones = map[string]string{
+	// classic
+	"airplane":    "Plane of Sky",
+	"akanon":      "Ak'Anon",
+	"arena":       "The Arena",
+	"befallen":    "Befallen",
+	"beholder":    "Gorge of King Xorbb",
+	"blackburrow": "Blackburrow",
+	"butcher":     "Butcherblock Mountains",
+	"cauldron":    "Dagnor's Cauldron",
+	"cazicthule":  "Lost Temple of Cazic-Thule",
+	"commons":     "West Commonlands",
+	"crushbone":   "Crushbone",
+	"cshome":      "Sunset Home",
+	"eastkarana":  "Eastern Karana",
+	"ecommons":    "East Commonlands",
+	"erudnext":    "Erudin",
+	"erudnint":    "Erudin Palace",
+	"erudsxing":   "Erud's Crossing",
+	"everfrost":   "Everfrost Peaks",
+	"feerrott":    "The Feerrott",
+	"felwithea":   "Northern Felwithe",
+	"felwitheb":   "Southern Felwithe",
+	"fearplane":   "Plane of Fear",
+	"freporte":    "East Freeport",
+	"freportn":    "North Freeport",
+	"freportw":    "West Freeport",
+	"gfaydark":    "Greater Faydark",
+	"grobb":       "Grobb",
+	"gukbottom":   "Ruins of Old Guk",
+	"guktop":      "Upper Guk",
+	"halas":       "Halas",
+	"hateplane":   "Plane of Hate",
+	"highkeep":    "High Keep",
+	"highpass":    "Highpass Hold",
+	"hole":        "The Hole",
+	"innothule":   "Innothule Swamp",
+	"kaladima":    "North Kaladim",
+	"kaladimb":    "South Kaladim",
+	"kedge":       "Kedge Keep",
+	"kerraridge":  "Kerra Isle",
+	"kithicor":    "Kithicor Forest",
+	"lakerathe":   "Lake Rathetear",
+	"lavastorm":   "Lavastorm Mountains",
+	"lfaydark":    "Lesser Faydark",
+	"mistmoore":   "Castle Mistmoore",
+	"misty":       "Misty Thicket",
+	"najena":      "Najena",
+	"nektulos":    "Nektulos Forest",
+	"neriaka":     "Neriak Foreign Quarter",
+	"neriakb":     "Neriak Commons",
+	"neriakc":     "Neriak Third Gate",
+	"northkarana": "Northern Karana",
+	"nro":         "Northern Desert of Ro",
+	"oasis":       "Oasis of Marr",
+	"oggok":       "Oggok",
+	"oot":         "Ocean of Tears",
+	"paineel":     "Paineel",
+	"paw":         "Infected Paw",
+	"permafrost":  "Permafrost Caverns",
+	"qcat":        "Qeynos Aqueduct System",
+	"qey2hh1":     "Western Plains of Karana",
+	"qeynos":      "South Qeynos",
+	"qeynos2":     "North Qeynos",
+	"qeytoqrg":    "Qeynos Hills",
+	"qrg":         "Surefall Glade",
+	"rathemtn":    "Rathe Mountains",
+	"rivervale":   "Rivervale",
+	"runnyeye":    "Runnyeye Citadel",
+	"soldunga":    "Solusek's Eye",
+	"soldungb":    "Nagafen's Lair",
+	"soltemple":   "Temple of Solusek Ro",
+	"southkarana": "Southern Karana",
+	"sro":         "Southern Desert of Ro",
+	"steamfont":   "Steamfont Mountains",
+	"stonebrunt":  "Stonebrunt Mountains",
+	"tox":         "Toxxulia Forest",
+	"unrest":      "Estate of Unrest",
+	"warrens":     "The Warrens",
+	// kunark
+	"burningwood":   "Burning Woods",
+	"cabeast":       "East Cabilis",
+	"cabwest":       "West Cabilis",
+	"charasis":      "Howling Stones",
+	"chardok":       "Chardok",
+	"citymist":      "City of Mist",
+	"dalnir":        "Crypt of Dalnir",
+	"dreadlands":    "Dreadlands",
+	"droga":         "Temple of Droga",
+	"emeraldjungle": "Emerald Jungle",
+	"fieldofbone":   "Field of Bone",
+	"firiona":       "Firiona Vie",
+	"frontiermtns":  "Frontier Mountains",
+	"kaesora":       "Kaesora",
+	"karnor":        "Karnor's Castle",
+	"kurn":          "Kurn's Tower",
+	"lakeofillomen": "Lake of Ill Omen",
+	"nurga":         "Mines of Nurga",
+	"overthere":     "The Overthere",
+	"sebilis":       "Old Sebilis",
+	"skyfire":       "Skyfire Mountains",
+	"swampofnohope": "Swamp of No Hope",
+	"timorous":      "Timorous Deep",
+	"trakanon":      "Trakanon's Teeth",
+	"veeshan":       "Veeshan's Peak",
+	"warslikswood":  "Warsliks Woods",
+	// velious
+	"cobaltscar":    "Cobalt Scar",
+	"crystal":       "Crystal Caverns",
+	"eastwastes":    "Eastern Wastes",
+	"frozenshadow":  "Tower of Frozen Shadow",
+	"greatdivide":   "Great Divide",
+	"growthplane":   "Plane of Growth",
+	"iceclad":       "Iceclad Ocean",
+	"kael":          "Kael Drakkel",
+	"mischiefplane": "Plane of Mischief",
+	"necropolis":    "Dragon Necropolis",
+	"sirens":        "Siren's Grotto",
+	"skyshrine":     "Skyshrine",
+	"sleeper":       "Sleeper's Tomb",
+	"templeveeshan": "Temple of Veeshan",
+	"thurgadina":    "Thurgadin",
+	"thurgadinb":    "Icewell Keep",
+	"velketor":      "Velketor's Labyrinth",
+	"wakening":      "Wakening Land",
+	"westwastes":    "Western Wastes",
+	// luclin
+	"acrylia":     "Acrylia Caverns",
+	"akheva":      "Akheva Ruins",
+	"dawnshroud":  "Dawnshroud Peaks",
+	"echo":        "Echo Caverns",
+	"fungusgrove": "Fungus Grove",
+	"griegsend":   "Grieg's End",
+	"grimling":    "Grimling Forest",
+	"hollowshade": "Hollowshade Moor",
+	"katta":       "Katta Castellum",
+	"letalis":     "Mons Letalis",
+	"maiden":      "Maiden's Eye",
+	"mseru":       "Marus Seru",
+	"netherbian":  "Netherbian Lair",
+	"nexus":       "The Nexus",
+	"paludal":     "Paludal Caverns",
+	"scarlet":     "Scarlet Desert",
+	"shadeweaver": "Shadeweaver's Thicket",
+	"shadowhaven": "Shadow Haven",
+	"sharvahl":    "Shar Vahl",
+	"sseru":       "Sanctus Seru",
+	"ssratemple":  "Ssraeshza Temple",
+	"tenebrous":   "Tenebrous Mountains",
+	"thedeep":     "The Deep",
+	"thegrey":     "The Grey",
+	"twilight":    "The Twilight Sea",
+	"umbral":      "Umbral Plains",
+	"vexthal":     "Vex Thal",
+	// planes of power
+	"airplane2":     "Plane of Air",
+	"bothunder":     "Bastion of Thunder",
+	"codecay":       "Crypt of Decay",
+	"hohonora":      "Halls of Honor",
+	"hohonorb":      "Temple of Marr",
+	"poair":         "Eryslai, the Kingdom of Wind",
+	"podisease":     "Plane of Disease",
+	"poeartha":      "Vegarlson, the Earthen Badlands",
+	"poearthb":      "Ragrax, Stronghold of the Twelve",
+	"pofire":        "Doomfire, the Burning Lands",
+	"poinnovation":  "Plane of Innovation",
+	"pojustice":     "Plane of Justice",
+	"poknowledge":   "Plane of Knowledge",
+	"ponightmare":   "Plane of Nightmare",
+	"postorms":      "Plane of Storms",
+	"potactics":     "Drunder, the Fortress of Zek",
+	"potimea":       "Plane of Time",
+	"potimeb":       "Plane of Time",
+	"potorment":     "Plane of Torment",
+	"potranquility": "Plane of Tranquility",
+	"povalor":       "Plane of Valor",
+	"powar":         "Plane of War",
+	"powater":       "Reef of Coirnav",
+	"solrotower":    "Solusek Ro's Tower",
+	// later
+	"abysmal":         "Abysmal Sea",
+	"barindu":         "Barindu, Hanging Gardens",
+	"bazaar":          "The Bazaar",
+	"guildhall":       "Guild Hall",
+	"guildlobby":      "Guild Lobby",
+	"natimbi":         "Natimbi, the Broken Shores",
+	"qinimi":          "Qinimi, Court of Nihilia",
+	"tutorial":        "The Tutorial",
+	"tutoriala":       "The Mines of Gloomingdeep",
+	"tutorialb":       "The Mines of Gloomingdeep",
+	"wallofslaughter": "Wall of Slaughter",
+}
@@ -0,0 +1,36 @@
+package zone
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"vergalid", true},
+		{"a", true},
+		{"zone2", true},
+		{strings.Repeat("a", MaxNameLength), true},
+		{strings.Repeat("a", MaxNameLength+1), false},
+		{"", false},
+		{"2zone", false},
+		{"Vergalid", false},
+		{"verGalid", false},
+		{"my zone", false},
+		{"zone.eqg", false},
+		{"zones/zone", false},
+		{"zones\\zone", false},
+		{"zone_obj", false},
+		{"zone-2", false},
+		{"zoné", false},
+	}
+	for _, tt := range tests {
+		err := ValidateName(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateName(%q) = %v, want ok %t", tt.name, err, tt.ok)
+		}
+	}
+}
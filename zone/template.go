@@ -0,0 +1,177 @@
+package zone
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultTemplate is the name of the template built into eqgzi-manager
+const DefaultTemplate = "default"
+
+// zoneVariable is replaced with the zone name in template file names and text files
+const zoneVariable = "{{zone}}"
+
+// Template is a set of files a new zone starts from, a folder or .zip in the templates folder
+type Template struct {
+	Name string
+	// Path is the folder or .zip of the template, empty for the built-in one
+	Path  string
+	files map[string][]byte
+}
+
+// Templates returns the built-in template with builtin files, followed by those in currentPath/templates
+func Templates(currentPath string, builtin map[string][]byte) ([]*Template, error) {
+	templates := []*Template{{Name: DefaultTemplate, files: builtin}}
+	dir := filepath.Join(currentPath, "templates")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templates, nil
+		}
+		return templates, fmt.Errorf("read templates: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() {
+			if strings.ToLower(filepath.Ext(name)) != ".zip" {
+				continue
+			}
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		name = strings.ToLower(name)
+		if name == DefaultTemplate {
+			continue
+		}
+		templates = append(templates, &Template{Name: name, Path: filepath.Join(dir, entry.Name())})
+	}
+	sort.Slice(templates[1:], func(i, j int) bool {
+		return templates[i+1].Name < templates[j+1].Name
+	})
+	return templates, nil
+}
+
+// FindTemplate returns the template called name, see Templates
+func FindTemplate(currentPath string, name string, builtin map[string][]byte) (*Template, error) {
+	templates, err := Templates(currentPath, builtin)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, t := range templates {
+		if t.Name == strings.ToLower(name) {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("template %s not found, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Files returns the files of the template for a zone called zone, with {{zone}} replaced in
+// file names and in the contents of text files
+func (t *Template) Files(zone string) (map[string][]byte, error) {
+	files := t.files
+	if t.Path != "" {
+		var err error
+		files, err = t.read()
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.Name, err)
+		}
+	}
+
+	out := map[string][]byte{}
+	for name, data := range files {
+		name = strings.ReplaceAll(name, zoneVariable, zone)
+		if strings.Contains(name, "%s") {
+			name = fmt.Sprintf(name, zone)
+		}
+		if isText(data) {
+			data = bytes.ReplaceAll(data, []byte(zoneVariable), []byte(zone))
+		}
+		out[name] = data
+	}
+	return out, nil
+}
+
+// Create makes a new zone folder named name from the template
+func (t *Template) Create(currentPath string, name string) error {
+	files, err := t.Files(name)
+	if err != nil {
+		return err
+	}
+	return Create(currentPath, name, files)
+}
+
+// read loads the files of a template folder or .zip, keyed by slash separated path
+func (t *Template) read() (map[string][]byte, error) {
+	fi, err := os.Stat(t.Path)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	if fi.IsDir() {
+		err = filepath.WalkDir(t.Path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(t.Path, p)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", rel, err)
+			}
+			files[filepath.ToSlash(rel)] = data
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return files, nil
+	}
+
+	zr, err := zip.OpenReader(t.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(zf.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("zip entry %s escapes the zone folder", zf.Name)
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", zf.Name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", zf.Name, err)
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+// isText returns true if data has no NUL bytes in its first 8000, like git's binary check
+func isText(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) == -1
+}
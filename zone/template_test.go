@@ -0,0 +1,195 @@
+package zone
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTemplateZip writes a template .zip of files into currentPath/templates
+func writeTemplateZip(t *testing.T, currentPath string, name string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(currentPath, "templates")
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	zw := zip.NewWriter(w)
+	for fileName, data := range files {
+		fw, err := zw.Create(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = fw.Write([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateFiles(t *testing.T) {
+	binary := "\x00{{zone}}"
+	tmpl := &Template{Name: DefaultTemplate, files: map[string][]byte{
+		"%s.blend":              []byte(binary),
+		"convert.bat":           []byte("eqgzi import {{zone}}.gltf\r\neqgzi export {{zone}}\r\n"),
+		"{{zone}}_lit.txt":      []byte("{{zone}}"),
+		"texture/%s_floor.png":  []byte("png"),
+		"texture/{{zone}}.json": []byte(`{"zone": "{{zone}}"}`),
+		"readme.txt":            []byte("a 100% plain file"),
+	}}
+	files, err := tmpl.Files("swamp")
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+	want := map[string]string{
+		"swamp.blend":             binary,
+		"convert.bat":             "eqgzi import swamp.gltf\r\neqgzi export swamp\r\n",
+		"swamp_lit.txt":           "swamp",
+		"texture/swamp_floor.png": "png",
+		"texture/swamp.json":      `{"zone": "swamp"}`,
+		"readme.txt":              "a 100% plain file",
+	}
+	got := map[string]string{}
+	for name, data := range files {
+		got[name] = string(data)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Files = %q, want %q", got, want)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	currentPath := t.TempDir()
+	for _, dir := range []string{"Swamp/texture", "empty"} {
+		err := os.MkdirAll(filepath.Join(currentPath, "templates", dir), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := os.WriteFile(filepath.Join(currentPath, "templates", "notes.txt"), nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTemplateZip(t, currentPath, "cave.ZIP", map[string]string{"%s.blend": "blend"})
+	writeTemplateZip(t, currentPath, "default.zip", map[string]string{"%s.blend": "blend"})
+
+	templates, err := Templates(currentPath, map[string][]byte{"%s.blend": []byte("builtin")})
+	if err != nil {
+		t.Fatalf("templates: %v", err)
+	}
+	names := []string{}
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	want := []string{DefaultTemplate, "cave", "empty", "swamp"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Templates = %v, want %v", names, want)
+	}
+	if templates[0].Path != "" {
+		t.Errorf("default template is %s, want the built-in one", templates[0].Path)
+	}
+
+	tmpl, err := FindTemplate(currentPath, "Cave", nil)
+	if err != nil || tmpl.Name != "cave" {
+		t.Errorf("FindTemplate(Cave) = %v, %v, want cave", tmpl, err)
+	}
+	_, err = FindTemplate(currentPath, "desert", nil)
+	if err == nil || !strings.Contains(err.Error(), "default, cave, empty, swamp") {
+		t.Errorf("FindTemplate(desert) error = %v, want the templates listed", err)
+	}
+
+	templates, err = Templates(filepath.Join(currentPath, "missing"), nil)
+	if err != nil || len(templates) != 1 {
+		t.Errorf("Templates of a workspace without templates = %d, %v, want the built-in one", len(templates), err)
+	}
+}
+
+func TestTemplateFolder(t *testing.T) {
+	currentPath := t.TempDir()
+	for _, file := range []string{"{{zone}}.blend", "texture/{{zone}}_floor.png", "convert.bat"} {
+		path := filepath.Join(currentPath, "templates", "swamp", file)
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(file), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpl, err := FindTemplate(currentPath, "swamp", nil)
+	if err != nil {
+		t.Fatalf("find template: %v", err)
+	}
+	err = tmpl.Create(currentPath, "marsh")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := []string{"convert.bat", "marsh.blend", "texture/marsh_floor.png"}
+	if got := zoneFiles(t, currentPath, "marsh"); !equalFiles(got, want) {
+		t.Errorf("zone files = %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(zonePath(currentPath, "marsh"), "marsh.blend"))
+	if err != nil || string(data) != "marsh.blend" {
+		t.Errorf("marsh.blend = %q, %v, want the zone name replaced", data, err)
+	}
+}
+
+func TestTemplateZip(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+		isErr bool
+	}{
+		{"files", map[string]string{"{{zone}}.blend": "blend", "texture/wood.png": "png", "convert.bat": "eqgzi {{zone}}"}, []string{"convert.bat", "marsh.blend", "texture/wood.png"}, false},
+		{"cleaned", map[string]string{"texture/../wood.png": "png", "./convert.bat": "bat"}, []string{"convert.bat", "wood.png"}, false},
+		{"parent", map[string]string{"../evil.txt": "evil"}, nil, true},
+		{"parent only", map[string]string{"..": "evil"}, nil, true},
+		{"nested parent", map[string]string{"texture/../../evil.txt": "evil"}, nil, true},
+		{"absolute", map[string]string{"/evil.txt": "evil"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentPath := t.TempDir()
+			writeTemplateZip(t, currentPath, "marsh.zip", tt.files)
+			tmpl, err := FindTemplate(currentPath, "marsh", nil)
+			if err != nil {
+				t.Fatalf("find template: %v", err)
+			}
+			err = tmpl.Create(currentPath, "marsh")
+			if tt.isErr {
+				if err == nil || !strings.Contains(err.Error(), "escapes") {
+					t.Fatalf("Create error = %v, want an escaping entry", err)
+				}
+				_, err = os.Stat(zonePath(currentPath, "marsh"))
+				if !os.IsNotExist(err) {
+					t.Errorf("rejected template created the zone folder: %v", err)
+				}
+				_, err = os.Stat(filepath.Join(currentPath, "evil.txt"))
+				if err == nil {
+					t.Error("zip entry was written outside of the zone folder")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			if got := zoneFiles(t, currentPath, "marsh"); !equalFiles(got, tt.want) {
+				t.Errorf("zone files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
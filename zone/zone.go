@@ -0,0 +1,76 @@
+package zone
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// List returns the zone folders found in currentPath/zones, creating it if missing
+func List(currentPath string) ([]string, error) {
+	zones := []string{}
+	dir := fmt.Sprintf("%s/zones", currentPath)
+	_, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		err = os.Mkdir(dir, os.ModePerm)
+		if err != nil {
+			return zones, fmt.Errorf("mkdir zone: %w", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return zones, fmt.Errorf("read dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		zones = append(zones, filepath.Base(entry.Name()))
+	}
+
+	return zones, nil
+}
+
+// Create makes a new zone folder named name and writes files into it.
+// Any %s in a file name is replaced with the zone name, and slashes make subfolders
+func Create(currentPath string, name string, files map[string][]byte) error {
+	dir, err := newZonePath(currentPath, name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("creating folder: %w", err)
+	}
+
+	names := []string{}
+	for fileName := range files {
+		names = append(names, fileName)
+	}
+	sort.Strings(names)
+
+	for _, fileName := range names {
+		data := files[fileName]
+		if strings.Contains(fileName, "%s") {
+			fileName = fmt.Sprintf(fileName, name)
+		}
+		filePath := filepath.Join(dir, filepath.FromSlash(fileName))
+		if !strings.HasPrefix(filePath, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("%s is outside of the zone folder", fileName)
+		}
+		err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("creating folder for %s: %w", fileName, err)
+		}
+		err = os.WriteFile(filePath, data, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", fileName, err)
+		}
+	}
+	return nil
+}